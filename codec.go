@@ -0,0 +1,191 @@
+package stream
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"github.com/chinalhr/go-stream/types"
+	"io"
+	"reflect"
+)
+
+//Codec Encodes elements to a writer and decodes them back from a reader, used by operations that move
+//elements out of memory, such as SortedExternal.
+//NewEncoder Returns an Encoder writing elements to w.
+//NewDecoder Returns a Decoder reading elements from r.
+type Codec interface {
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+//Encoder Writes elements one by one.
+type Encoder interface {
+	Encode(e types.T) error
+}
+
+//Decoder Reads elements one by one, Decode returns io.EOF when there are no more elements.
+type Decoder interface {
+	Decode() (types.T, error)
+}
+
+//GobCodec Returns a Codec based on encoding/gob.
+//The concrete types of the elements need to be registered by gob.Register.
+func GobCodec() Codec {
+	return gobCodec{}
+}
+
+//JSONCodec Returns a Codec based on encoding/json, elements are written one JSON value per line.
+//newElem returns a pointer to a new value that an element is decoded into, the decoded element is the value
+//the pointer points to. If newElem is nil, elements are decoded as interface{} values.
+func JSONCodec(newElem func() types.T) Codec {
+	return jsonCodec{newElem: newElem}
+}
+
+type gobCodec struct{}
+
+func (gobCodec) NewEncoder(w io.Writer) Encoder {
+	return &gobEncoder{enc: gob.NewEncoder(w)}
+}
+
+func (gobCodec) NewDecoder(r io.Reader) Decoder {
+	return &gobDecoder{dec: gob.NewDecoder(r)}
+}
+
+type gobEncoder struct {
+	enc *gob.Encoder
+}
+
+func (e *gobEncoder) Encode(element types.T) error {
+	return e.enc.Encode(&element)
+}
+
+//gobDecoder Decodes interface values, or values of a single type into the pointers returned by newElem.
+type gobDecoder struct {
+	dec     *gob.Decoder
+	newElem func() types.T
+}
+
+func (d *gobDecoder) Decode() (types.T, error) {
+	if d.newElem != nil {
+		ptr := d.newElem()
+		if err := d.dec.Decode(ptr); err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(ptr).Elem().Interface(), nil
+	}
+	var element types.T
+	if err := d.dec.Decode(&element); err != nil {
+		return nil, err
+	}
+	return element, nil
+}
+
+type jsonCodec struct {
+	newElem func() types.T
+}
+
+func (c jsonCodec) NewEncoder(w io.Writer) Encoder {
+	return &jsonEncoder{enc: json.NewEncoder(w)}
+}
+
+func (c jsonCodec) NewDecoder(r io.Reader) Decoder {
+	return &jsonDecoder{dec: json.NewDecoder(r), newElem: c.newElem}
+}
+
+type jsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *jsonEncoder) Encode(element types.T) error {
+	return e.enc.Encode(element)
+}
+
+type jsonDecoder struct {
+	dec     *json.Decoder
+	newElem func() types.T
+}
+
+func (d *jsonDecoder) Decode() (types.T, error) {
+	return decodeJSON(d.dec.Decode, d.newElem)
+}
+
+//decodeJSON Decode a JSON value by decode into a new value returned by newElem.
+func decodeJSON(decode func(v interface{}) error, newElem func() types.T) (types.T, error) {
+	if newElem == nil {
+		var element types.T
+		if err := decode(&element); err != nil {
+			return nil, err
+		}
+		return element, nil
+	}
+	ptr := newElem()
+	if err := decode(ptr); err != nil {
+		return nil, err
+	}
+	return reflect.ValueOf(ptr).Elem().Interface(), nil
+}
+
+//decoderIterator A general type iterator reading elements from a Decoder.
+//The size is unknown unless given, the first decoding error other than io.EOF ends the iterator and is
+//returned by Err. reader is the reader of the Decoder, closed by Close if it is an io.Closer.
+type decoderIterator struct {
+	dec     Decoder
+	reader  io.Reader
+	size    int
+	next    types.T
+	hasNext bool
+	done    bool
+	err     error
+}
+
+func (it *decoderIterator) GetSize() int {
+	return it.size
+}
+
+func (it *decoderIterator) HasNext() bool {
+	if it.hasNext {
+		return true
+	}
+	if it.done {
+		return false
+	}
+	e, err := it.dec.Decode()
+	if err != nil {
+		it.done = true
+		if err != io.EOF {
+			it.err = err
+		}
+		return false
+	}
+	it.next = e
+	it.hasNext = true
+	return true
+}
+
+func (it *decoderIterator) Next() types.T {
+	it.HasNext()
+	e := it.next
+	it.next = nil
+	it.hasNext = false
+	return e
+}
+
+func (it *decoderIterator) Err() error {
+	return it.err
+}
+
+func (it *decoderIterator) Close() error {
+	it.done = true
+	closer, ok := it.reader.(io.Closer)
+	if !ok {
+		return nil
+	}
+	it.reader = nil
+	return closer.Close()
+}
+
+func buildDecoderIterator(dec Decoder, size int) *decoderIterator {
+	return &decoderIterator{
+		dec:  dec,
+		size: size,
+	}
+}
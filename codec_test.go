@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"bytes"
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type codecWidget struct {
+	Color  string
+	Weight int
+}
+
+func TestCodec(t *testing.T) {
+	tests := []struct {
+		name   string
+		codec  Codec
+		input  []types.T
+		actual []types.T
+	}{
+		{
+			name:   "gobCase",
+			codec:  GobCodec(),
+			input:  []types.T{1, 2, 3},
+			actual: []types.T{1, 2, 3},
+		},
+		{
+			name:   "jsonCase",
+			codec:  JSONCodec(func() types.T { return &codecWidget{} }),
+			input:  []types.T{codecWidget{"red", 1}, codecWidget{"blue", 2}},
+			actual: []types.T{codecWidget{"red", 1}, codecWidget{"blue", 2}},
+		},
+		{
+			name:   "jsonInterfaceCase",
+			codec:  JSONCodec(nil),
+			input:  []types.T{"a", 1},
+			actual: []types.T{"a", float64(1)},
+		},
+		{
+			name:   "emptyCase",
+			codec:  GobCodec(),
+			input:  []types.T{},
+			actual: []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := test.codec.NewEncoder(&buf)
+			for _, e := range test.input {
+				assert.Nil(t, enc.Encode(e))
+			}
+			it := buildDecoderIterator(test.codec.NewDecoder(&buf), -1)
+			result := make([]types.T, 0)
+			for it.HasNext() {
+				result = append(result, it.Next())
+			}
+			assert.Nil(t, it.Err())
+			assert.Equal(t, test.actual, result)
+		})
+	}
+}
+
+func TestDecoderIterator_Err(t *testing.T) {
+	it := buildDecoderIterator(JSONCodec(nil).NewDecoder(bytes.NewBufferString("1\n{")), -1)
+	assert.Equal(t, true, it.HasNext())
+	assert.Equal(t, float64(1), it.Next())
+	assert.Equal(t, false, it.HasNext())
+	assert.NotNil(t, it.Err())
+}
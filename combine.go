@@ -0,0 +1,306 @@
+package stream
+
+import (
+	"github.com/chinalhr/go-stream/types"
+)
+
+//Concat Return a Stream of the elements of the first stream, followed by the elements of the second stream and so on.
+//The size of the Stream is the sum of the sizes of streams when they are all known.
+func Concat(streams ...Stream) Stream {
+	pipeline := newPipeline(&concatIterator{multiIterator: multiIterator{sources: buildStreamIterators(streams)}})
+	return Stream{pipeline}
+}
+
+//Interleave Return a Stream taking one element from each of streams in turn, a stream that has no more elements
+//is skipped. The size of the Stream is the sum of the sizes of streams when they are all known.
+func Interleave(streams ...Stream) Stream {
+	pipeline := newPipeline(&interleaveIterator{multiIterator: multiIterator{sources: buildStreamIterators(streams)}})
+	return Stream{pipeline}
+}
+
+//Zip Return a Stream of combiner applied to the elements of a and b pairwise, the Stream ends when either
+//a or b has no more elements.
+func Zip(a Stream, b Stream, combiner func(e1 types.T, e2 types.T) types.R) Stream {
+	pipeline := newPipeline(&zipIterator{
+		multiIterator: multiIterator{sources: []iterator{buildStreamIterator(a), buildStreamIterator(b)}},
+		combiner:      combiner,
+	})
+	return Stream{pipeline}
+}
+
+//ZipLongest Return a Stream of combiner applied to the elements of a and b pairwise, the Stream ends when both
+//a and b have no more elements, the missing elements of the shorter stream are nil.
+func ZipLongest(a Stream, b Stream, combiner func(e1 types.T, e2 types.T) types.R) Stream {
+	pipeline := newPipeline(&zipIterator{
+		multiIterator: multiIterator{sources: []iterator{buildStreamIterator(a), buildStreamIterator(b)}},
+		combiner:      combiner,
+		longest:       true,
+	})
+	return Stream{pipeline}
+}
+
+//MergeSorted Return a Stream merging streams that are each sorted by the compare function, the elements are
+//merged lazily through a heap of the heads of streams, elements that compare equal are taken from the earlier
+//stream first. The Stream is marked SORTED, so a following Sorted only verifies the order.
+func MergeSorted(compare func(first types.T, second types.T) int, streams ...Stream) Stream {
+	pipeline := newPipeline(buildMergeIterator(compare, buildStreamIterators(streams)...))
+	pipeline.currentOpt.sorted = true
+	return Stream{pipeline}
+}
+
+//MergeSortedDistinct Return a Stream like MergeSorted, keeping only the first of the elements that compare equal.
+func MergeSortedDistinct(compare func(first types.T, second types.T) int, streams ...Stream) Stream {
+	pipeline := newPipeline(&distinctSortedIterator{
+		source:  buildMergeIterator(compare, buildStreamIterators(streams)...),
+		compare: compare,
+	})
+	pipeline.currentOpt.sorted = true
+	return Stream{pipeline}
+}
+
+//ZipWithIndex Returns a Stream of types.KV with the index of the element in KEY and the element in VALUE.
+//ZipWithIndex needs the elements in encounter order, so a Parallel Stream is evaluated sequentially.
+func (s Stream) ZipWithIndex() Stream {
+	pipeline := s.p
+	pipeline.addOperation(func(next stage) stage {
+		var index int
+		return newDefaultIntermediateStage(next, beginFunc(func(size int) {
+			index = 0
+			next.Begin(size)
+		}), acceptFunc(func(e types.T) {
+			next.Accept(types.KV{KEY: index, VALUE: e})
+			index++
+		}))
+	})
+	pipeline.currentOpt.ordered = true
+	return s
+}
+
+//streamIterator Pulls the elements of a Stream. The pipeline of the Stream is evaluated by a goroutine which is
+//started by the first HasNext, and stopped by Close.
+type streamIterator struct {
+	p        *referencePipeline
+	values   chan types.T
+	done     chan struct{}
+	finished chan struct{}
+	next     types.T
+	hasNext  bool
+	started  bool
+	closed   bool
+}
+
+//buildStreamIterator Returns an iterator over the elements of s. A Stream without intermediate operations is
+//iterated by its source iterator directly, which keeps the size of the source.
+func buildStreamIterator(s Stream) iterator {
+	if s.p.currentOpt.preOpt == nil && s.p.workers <= 1 {
+		return s.p.it
+	}
+	return &streamIterator{
+		p:        s.p,
+		values:   make(chan types.T),
+		done:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+}
+
+func buildStreamIterators(streams []Stream) []iterator {
+	sources := make([]iterator, 0, len(streams))
+	for _, s := range streams {
+		sources = append(sources, buildStreamIterator(s))
+	}
+	return sources
+}
+
+func (it *streamIterator) start() {
+	it.started = true
+	go func() {
+		defer close(it.finished)
+		defer close(it.values)
+		it.p.evaluate(newDefaultTerminalStage(
+			acceptFunc(func(e types.T) {
+				select {
+				case it.values <- e:
+				case <-it.done:
+				}
+			}),
+			cancellationRequestedFunc(func() bool {
+				select {
+				case <-it.done:
+					return true
+				default:
+					return false
+				}
+			}),
+		))
+	}()
+}
+
+func (it *streamIterator) GetSize() int {
+	return -1
+}
+
+func (it *streamIterator) HasNext() bool {
+	if it.hasNext {
+		return true
+	}
+	if it.closed {
+		return false
+	}
+	if !it.started {
+		it.start()
+	}
+	e, ok := <-it.values
+	if !ok {
+		return false
+	}
+	it.next, it.hasNext = e, true
+	return true
+}
+
+func (it *streamIterator) Next() types.T {
+	it.HasNext()
+	e := it.next
+	it.next, it.hasNext = nil, false
+	return e
+}
+
+func (it *streamIterator) Err() error {
+	return it.p.getErr()
+}
+
+func (it *streamIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	close(it.done)
+	if it.started {
+		<-it.finished
+	}
+	return nil
+}
+
+//multiIterator The common part of the iterators combining several sources.
+type multiIterator struct {
+	sources []iterator
+}
+
+func (it *multiIterator) sumSize() int {
+	size := 0
+	for _, source := range it.sources {
+		if source.GetSize() == -1 {
+			return -1
+		}
+		size += source.GetSize()
+	}
+	return size
+}
+
+func (it *multiIterator) Err() error {
+	for _, source := range it.sources {
+		if source, ok := source.(errIterator); ok && source.Err() != nil {
+			return source.Err()
+		}
+	}
+	return nil
+}
+
+func (it *multiIterator) Close() error {
+	var closeErr error
+	for _, source := range it.sources {
+		if source, ok := source.(closableIterator); ok {
+			if err := source.Close(); err != nil && closeErr == nil {
+				closeErr = err
+			}
+		}
+	}
+	return closeErr
+}
+
+//concatIterator Iterates the sources one after another.
+type concatIterator struct {
+	multiIterator
+	current int
+}
+
+func (it *concatIterator) GetSize() int {
+	return it.sumSize()
+}
+
+func (it *concatIterator) HasNext() bool {
+	for ; it.current < len(it.sources); it.current++ {
+		if it.sources[it.current].HasNext() {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *concatIterator) Next() types.T {
+	it.HasNext()
+	return it.sources[it.current].Next()
+}
+
+//interleaveIterator Iterates the sources in turn, skipping the exhausted ones.
+type interleaveIterator struct {
+	multiIterator
+	current int
+}
+
+func (it *interleaveIterator) GetSize() int {
+	return it.sumSize()
+}
+
+func (it *interleaveIterator) HasNext() bool {
+	for i := 0; i < len(it.sources); i++ {
+		if it.sources[(it.current+i)%len(it.sources)].HasNext() {
+			it.current = (it.current + i) % len(it.sources)
+			return true
+		}
+	}
+	return false
+}
+
+func (it *interleaveIterator) Next() types.T {
+	it.HasNext()
+	e := it.sources[it.current].Next()
+	it.current = (it.current + 1) % len(it.sources)
+	return e
+}
+
+//zipIterator Iterates two sources pairwise.
+type zipIterator struct {
+	multiIterator
+	combiner func(e1 types.T, e2 types.T) types.R
+	longest  bool
+}
+
+func (it *zipIterator) GetSize() int {
+	first, second := it.sources[0].GetSize(), it.sources[1].GetSize()
+	if first == -1 || second == -1 {
+		return -1
+	}
+	if (first < second) != it.longest {
+		return first
+	}
+	return second
+}
+
+func (it *zipIterator) HasNext() bool {
+	first, second := it.sources[0].HasNext(), it.sources[1].HasNext()
+	if it.longest {
+		return first || second
+	}
+	return first && second
+}
+
+func (it *zipIterator) Next() types.T {
+	var e1, e2 types.T
+	if it.sources[0].HasNext() {
+		e1 = it.sources[0].Next()
+	}
+	if it.sources[1].HasNext() {
+		e2 = it.sources[1].Next()
+	}
+	return it.combiner(e1, e2)
+}
@@ -0,0 +1,226 @@
+package stream
+
+import (
+	"errors"
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStream_Concat(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []Stream
+		size   int
+		actual []types.T
+	}{
+		{
+			name:   "normalCase",
+			input:  []Stream{OfElements(1, 2), OfElements(), OfElements(3)},
+			size:   3,
+			actual: []types.T{1, 2, 3},
+		},
+		{
+			name: "intermediateOperationCase",
+			input: []Stream{OfElements(1, 2, 3, 4).Filter(func(e types.T) bool {
+				return e.(int)%2 == 0
+			}), OfElements(5)},
+			size:   -1,
+			actual: []types.T{2, 4, 5},
+		},
+		{
+			name:   "emptyCase",
+			input:  []Stream{},
+			size:   0,
+			actual: []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := Concat(test.input...)
+			assert.Equal(t, test.size, s.p.it.GetSize())
+			assert.Equal(t, test.actual, s.ToSlice())
+		})
+	}
+}
+
+func TestStream_Interleave(t *testing.T) {
+	result := Interleave(OfElements(1, 4, 6, 7), OfElements(2), Range(3, 6, 2).Map(func(e types.T) (r types.R) {
+		return e.(int) * 10
+	})).ToSlice()
+	assert.Equal(t, []types.T{1, 2, 30, 4, 50, 6, 7}, result)
+}
+
+func TestStream_Zip(t *testing.T) {
+	pair := func(e1 types.T, e2 types.T) types.R {
+		return types.KV{KEY: e1, VALUE: e2}
+	}
+
+	tests := []struct {
+		name   string
+		stream Stream
+		size   int
+		actual []types.T
+	}{
+		{
+			name:   "shortestCase",
+			stream: Zip(OfElements(1, 2, 3), OfElements("a", "b"), pair),
+			size:   2,
+			actual: []types.T{types.KV{KEY: 1, VALUE: "a"}, types.KV{KEY: 2, VALUE: "b"}},
+		},
+		{
+			name:   "longestCase",
+			stream: ZipLongest(OfElements(1, 2, 3), OfElements("a", "b"), pair),
+			size:   3,
+			actual: []types.T{types.KV{KEY: 1, VALUE: "a"}, types.KV{KEY: 2, VALUE: "b"}, types.KV{KEY: 3, VALUE: nil}},
+		},
+		{
+			name:   "infiniteCase",
+			stream: Zip(Iterate(0, func(e types.T) types.T { return e.(int) + 1 }), OfElements("a", "b"), pair),
+			size:   -1,
+			actual: []types.T{types.KV{KEY: 0, VALUE: "a"}, types.KV{KEY: 1, VALUE: "b"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.size, test.stream.p.it.GetSize())
+			assert.Equal(t, test.actual, test.stream.ToSlice())
+		})
+	}
+}
+
+func TestStream_ZipWithIndex(t *testing.T) {
+	result := OfElements("a", "b", "c").ZipWithIndex().ToSlice()
+	assert.Equal(t, []types.T{
+		types.KV{KEY: 0, VALUE: "a"},
+		types.KV{KEY: 1, VALUE: "b"},
+		types.KV{KEY: 2, VALUE: "c"},
+	}, result)
+
+	parallel := Range(0, 1000, 1).Parallel(8).ZipWithIndex().ToSlice()
+	assert.Equal(t, 1000, len(parallel))
+	for i, e := range parallel {
+		assert.Equal(t, types.KV{KEY: i, VALUE: i}, e)
+	}
+}
+
+func TestStreamIterator_ShortCircuit(t *testing.T) {
+	generated := 0
+	infinite := Generate(func() types.T {
+		generated++
+		return generated
+	}).Map(func(e types.T) (r types.R) {
+		return e
+	})
+
+	it := buildStreamIterator(infinite).(*streamIterator)
+	s := Stream{newPipeline(&concatIterator{multiIterator: multiIterator{sources: []iterator{it}}})}
+	assert.Equal(t, 1, s.FindFirst())
+	assert.Equal(t, true, it.closed)
+	select {
+	case <-it.finished:
+	default:
+		assert.Fail(t, "stream goroutine is still running")
+	}
+}
+
+type failingIterator struct {
+	iteratorInfiniteBaseInfo
+}
+
+func (it *failingIterator) HasNext() bool {
+	return false
+}
+
+func (it *failingIterator) Next() types.T {
+	return nil
+}
+
+func (it *failingIterator) Err() error {
+	return errors.New("failed")
+}
+
+func TestStreamIterator_Err(t *testing.T) {
+	failing := Stream{newPipeline(&failingIterator{})}.Map(func(e types.T) (r types.R) {
+		return e
+	})
+	s := Concat(OfElements(1), failing)
+	assert.Equal(t, []types.T{1}, s.ToSlice())
+	assert.EqualError(t, s.Err(), "failed")
+}
+
+func TestStream_MergeSorted(t *testing.T) {
+	compare := func(first types.T, second types.T) int {
+		return first.(types.KV).KEY.(int) - second.(types.KV).KEY.(int)
+	}
+	streams := func() []Stream {
+		return []Stream{
+			OfElements(types.KV{KEY: 1, VALUE: "a"}, types.KV{KEY: 3, VALUE: "a"}, types.KV{KEY: 5, VALUE: "a"}),
+			OfElements(types.KV{KEY: 1, VALUE: "b"}, types.KV{KEY: 2, VALUE: "b"}, types.KV{KEY: 6, VALUE: "b"}).
+				Filter(func(e types.T) bool {
+					return e.(types.KV).KEY.(int) != 6
+				}),
+			OfElements(),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		stream Stream
+		actual []types.T
+	}{
+		{
+			name:   "mergeCase",
+			stream: MergeSorted(compare, streams()...),
+			actual: []types.T{
+				types.KV{KEY: 1, VALUE: "a"}, types.KV{KEY: 1, VALUE: "b"}, types.KV{KEY: 2, VALUE: "b"},
+				types.KV{KEY: 3, VALUE: "a"}, types.KV{KEY: 5, VALUE: "a"},
+			},
+		},
+		{
+			name:   "distinctCase",
+			stream: MergeSortedDistinct(compare, streams()...),
+			actual: []types.T{
+				types.KV{KEY: 1, VALUE: "a"}, types.KV{KEY: 2, VALUE: "b"},
+				types.KV{KEY: 3, VALUE: "a"}, types.KV{KEY: 5, VALUE: "a"},
+			},
+		},
+		{
+			name:   "emptyCase",
+			stream: MergeSorted(compare),
+			actual: []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.actual, test.stream.ToSlice())
+		})
+	}
+}
+
+func TestStream_MergeSortedThenSorted(t *testing.T) {
+	compare := func(first types.T, second types.T) int {
+		return first.(int) - second.(int)
+	}
+	compared := 0
+	countingCompare := func(first types.T, second types.T) int {
+		compared++
+		return compare(first, second)
+	}
+
+	s := MergeSorted(compare, OfElements(1, 4, 7), OfElements(2, 5, 8), OfElements(3, 6, 9)).
+		Filter(func(e types.T) bool {
+			return e.(int) != 5
+		})
+	assert.Equal(t, true, s.p.currentOpt.sorted)
+	assert.Equal(t, []types.T{1, 2, 3, 4, 6, 7, 8, 9}, s.Sorted(countingCompare).ToSlice())
+	assert.Equal(t, 7, compared)
+
+	mapped := MergeSorted(compare, OfElements(1, 4, 7), OfElements(2, 5, 8)).Map(func(e types.T) (r types.R) {
+		return e
+	})
+	assert.Equal(t, false, mapped.p.currentOpt.sorted)
+}
@@ -0,0 +1,244 @@
+package stream
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/chinalhr/go-stream/types"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+//CommandError Is the error of a command that could not be run or exited unsuccessfully, Stderr is the output the
+//command wrote to its standard error, if it was captured.
+type CommandError struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("command %s: %v", strings.Join(e.Args, " "), e.Err)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		msg += ": " + stderr
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+//OfCommand Return a sequential Stream of the lines that cmd writes to its standard output. cmd is started by the
+//first element pulled, its standard error is captured unless cmd.Stderr is set. An unsuccessful exit of cmd is
+//returned by Err as a *CommandError holding the captured standard error. If the evaluation is short-circuited,
+//cmd is killed. WithSplit and WithMaxTokenSize configure the lines like OfLines.
+func OfCommand(cmd *exec.Cmd, opts ...IOOption) Stream {
+	pipeline := newPipeline(&commandIterator{
+		cmd:     cmd,
+		options: newIOOptions(opts),
+	})
+	return Stream{pipeline}
+}
+
+//PipeThrough Returns a Stream of the lines that cmd writes to its standard output, while the elements of this
+//Stream are written to its standard input one per line. An element is written as itself if it is a string or
+//[]byte, by String if it is a fmt.Stringer, and by fmt.Sprint otherwise. cmd is started when the evaluation
+//begins, and its standard input is closed when the elements end. An unsuccessful exit of cmd fails the Stream
+//with a *CommandError returned by Err. If the evaluation is short-circuited downstream, cmd is killed and the
+//elements stop flowing into it. If cmd stops reading its standard input, the remaining elements are dropped.
+func (s Stream) PipeThrough(cmd *exec.Cmd, opts ...IOOption) Stream {
+	pipeline := s.p
+	o := newIOOptions(opts)
+	pipeline.addOperation(func(next stage) stage {
+		var stdin io.WriteCloser
+		var stderr *bytes.Buffer
+		var output chan struct{}
+		var cancelled, inputClosed int32
+		var started bool
+		var mutex sync.Mutex
+		//next is only called by the goroutine reading the output of cmd between Begin and End, which reports the
+		//cancellation of next through cancelled. Begin is called again by an upstream sort once its elements are
+		//sorted, cmd is only started by the first call.
+		return newDefaultIntermediateStage(next, beginFunc(func(size int) {
+			if started {
+				return
+			}
+			started = true
+			atomic.StoreInt32(&cancelled, 0)
+			atomic.StoreInt32(&inputClosed, 0)
+			next.Begin(-1)
+			var stdout io.Reader
+			var err error
+			stdin, stdout, stderr, err = startCommand(cmd, true)
+			if err != nil {
+				pipeline.fail(err)
+				return
+			}
+			output = make(chan struct{})
+			go func() {
+				defer close(output)
+				lines := buildLinesIterator(stdout, o)
+				for {
+					if next.CancellationRequested() {
+						atomic.StoreInt32(&cancelled, 1)
+						_ = cmd.Process.Kill()
+						return
+					}
+					if !lines.HasNext() {
+						break
+					}
+					next.Accept(lines.Next())
+				}
+				if err := lines.Err(); err != nil {
+					pipeline.fail(err)
+					_ = cmd.Process.Kill()
+				}
+			}()
+		}), acceptFunc(func(e types.T) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if stdin == nil || atomic.LoadInt32(&inputClosed) == 1 {
+				return
+			}
+			if _, err := io.WriteString(stdin, lineOf(e)+"\n"); err != nil {
+				atomic.StoreInt32(&inputClosed, 1)
+			}
+		}), cancellationRequestedFunc(func() bool {
+			return pipeline.failed() || atomic.LoadInt32(&cancelled) == 1 || atomic.LoadInt32(&inputClosed) == 1
+		}), endFunc(func() {
+			defer next.End()
+			started = false
+			if stdin == nil {
+				return
+			}
+			_ = stdin.Close()
+			stdin = nil
+			killed := pipeline.failed()
+			if killed {
+				_ = cmd.Process.Kill()
+			}
+			<-output
+			if killed || atomic.LoadInt32(&cancelled) == 1 {
+				_ = cmd.Wait()
+				return
+			}
+			if err := waitCommand(cmd, stderr); err != nil {
+				pipeline.fail(err)
+			}
+		}))
+	})
+	return s
+}
+
+//startCommand Start cmd with its standard output piped, its standard input piped if withStdin is true, and its
+//standard error captured into the returned buffer unless cmd.Stderr is set.
+func startCommand(cmd *exec.Cmd, withStdin bool) (io.WriteCloser, io.Reader, *bytes.Buffer, error) {
+	var stdin io.WriteCloser
+	var err error
+	if withStdin {
+		if stdin, err = cmd.StdinPipe(); err != nil {
+			return nil, nil, nil, &CommandError{Args: cmd.Args, Err: err}
+		}
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, nil, &CommandError{Args: cmd.Args, Err: err}
+	}
+	var stderr *bytes.Buffer
+	if cmd.Stderr == nil {
+		stderr = &bytes.Buffer{}
+		cmd.Stderr = stderr
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, nil, &CommandError{Args: cmd.Args, Err: err}
+	}
+	return stdin, stdout, stderr, nil
+}
+
+//waitCommand Wait for cmd to exit, an unsuccessful exit is returned as a *CommandError.
+func waitCommand(cmd *exec.Cmd, stderr *bytes.Buffer) error {
+	if err := cmd.Wait(); err != nil {
+		commandErr := &CommandError{Args: cmd.Args, Err: err}
+		if stderr != nil {
+			commandErr.Stderr = stderr.String()
+		}
+		return commandErr
+	}
+	return nil
+}
+
+//lineOf Returns the text of e written as a line to a command.
+func lineOf(e types.T) string {
+	switch text := e.(type) {
+	case string:
+		return text
+	case []byte:
+		return string(text)
+	case fmt.Stringer:
+		return text.String()
+	}
+	return fmt.Sprint(e)
+}
+
+//commandIterator A general type iterator of the output lines of a command, the command is started by the first
+//HasNext and waited for once its output ends.
+type commandIterator struct {
+	iteratorInfiniteBaseInfo
+	cmd     *exec.Cmd
+	options *ioOptions
+	lines   *scannerIterator
+	stderr  *bytes.Buffer
+	started bool
+	done    bool
+	err     error
+}
+
+func (it *commandIterator) HasNext() bool {
+	if it.done {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		_, stdout, stderr, err := startCommand(it.cmd, false)
+		if err != nil {
+			it.err, it.done = err, true
+			return false
+		}
+		it.lines, it.stderr = buildLinesIterator(stdout, it.options), stderr
+	}
+	if it.lines.HasNext() {
+		return true
+	}
+	it.done = true
+	if it.err = it.lines.Err(); it.err != nil {
+		_ = it.cmd.Process.Kill()
+		_ = it.cmd.Wait()
+		return false
+	}
+	it.err = waitCommand(it.cmd, it.stderr)
+	return false
+}
+
+func (it *commandIterator) Next() types.T {
+	it.HasNext()
+	return it.lines.Next()
+}
+
+func (it *commandIterator) Err() error {
+	return it.err
+}
+
+//Close Kill the command if its output has not ended.
+func (it *commandIterator) Close() error {
+	if !it.started || it.done {
+		it.done = true
+		return nil
+	}
+	it.done = true
+	_ = it.cmd.Process.Kill()
+	_ = it.cmd.Wait()
+	return nil
+}
@@ -0,0 +1,104 @@
+package stream
+
+import (
+	"errors"
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"os/exec"
+	"testing"
+)
+
+func lookPath(t *testing.T, names ...string) {
+	for _, name := range names {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skipf("%s is not available", name)
+		}
+	}
+}
+
+func TestOfCommand(t *testing.T) {
+	lookPath(t, "sh", "yes")
+
+	t.Run("linesCase", func(t *testing.T) {
+		s := OfCommand(exec.Command("sh", "-c", "printf 'a\\nb\\nc'"))
+		assert.Equal(t, []types.T{"a", "b", "c"}, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+
+	t.Run("exitErrCase", func(t *testing.T) {
+		s := OfCommand(exec.Command("sh", "-c", "echo out; echo boom >&2; exit 3"))
+		assert.Equal(t, []types.T{"out"}, s.ToSlice())
+		var commandErr *CommandError
+		assert.True(t, errors.As(s.Err(), &commandErr))
+		assert.Equal(t, "boom\n", commandErr.Stderr)
+		var exitErr *exec.ExitError
+		assert.True(t, errors.As(s.Err(), &exitErr))
+		assert.Equal(t, 3, exitErr.ExitCode())
+	})
+
+	t.Run("shortCircuitCase", func(t *testing.T) {
+		cmd := exec.Command("yes")
+		s := OfCommand(cmd)
+		assert.Equal(t, []types.T{"y", "y", "y"}, s.Limit(3).ToSlice())
+		assert.NoError(t, s.Err())
+		assert.NotNil(t, cmd.ProcessState)
+	})
+
+	t.Run("startErrCase", func(t *testing.T) {
+		s := OfCommand(exec.Command("/nonexistent/command"))
+		assert.Equal(t, 0, s.Count())
+		assert.Error(t, s.Err())
+	})
+}
+
+func TestStream_PipeThrough(t *testing.T) {
+	lookPath(t, "sh", "sort", "cat", "head")
+
+	t.Run("sortCase", func(t *testing.T) {
+		s := OfElements("b", "c", "a").PipeThrough(exec.Command("sort"))
+		assert.Equal(t, []types.T{"a", "b", "c"}, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+
+	t.Run("afterSortCase", func(t *testing.T) {
+		cmd := exec.Command("cat")
+		s := OfElements(3, 1, 2).Sorted(func(first types.T, second types.T) int {
+			return first.(int) - second.(int)
+		}).PipeThrough(cmd)
+		assert.Equal(t, []types.T{"1", "2", "3"}, s.ToSlice())
+		assert.NoError(t, s.Err())
+		assert.NotNil(t, cmd.ProcessState)
+	})
+
+	t.Run("parallelCase", func(t *testing.T) {
+		s := RangeClosed(1, 100, 1).Parallel(4).PipeThrough(exec.Command("sort", "-n"))
+		assert.Equal(t, 100, s.Count())
+		assert.NoError(t, s.Err())
+	})
+
+	t.Run("cancellationCase", func(t *testing.T) {
+		cmd := exec.Command("cat")
+		s := Generate(func() types.T {
+			return "x"
+		}).PipeThrough(cmd).Limit(5)
+		assert.Equal(t, 5, s.Count())
+		assert.NoError(t, s.Err())
+		assert.NotNil(t, cmd.ProcessState)
+	})
+
+	t.Run("inputClosedCase", func(t *testing.T) {
+		s := Generate(func() types.T {
+			return 1
+		}).PipeThrough(exec.Command("head", "-n", "2"))
+		assert.Equal(t, []types.T{"1", "1"}, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+
+	t.Run("exitErrCase", func(t *testing.T) {
+		s := OfElements(1, 2).PipeThrough(exec.Command("sh", "-c", "cat; echo failed >&2; exit 2"))
+		assert.Equal(t, []types.T{"1", "2"}, s.ToSlice())
+		var commandErr *CommandError
+		assert.True(t, errors.As(s.Err(), &commandErr))
+		assert.Equal(t, "failed\n", commandErr.Stderr)
+	})
+}
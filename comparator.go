@@ -18,3 +18,23 @@ func (c *Comparator) Less(i, j int) bool {
 func (c *Comparator) Swap(i, j int) {
 	c.source[i], c.source[j] = c.source[j], c.source[i]
 }
+
+//chainCompare Returns a compare function that orders elements by compare, and orders elements that compare equal
+//by the thenCompare functions in turn.
+func chainCompare(compare func(e1 types.T, e2 types.T) int,
+	thenCompare ...func(e1 types.T, e2 types.T) int) func(e1 types.T, e2 types.T) int {
+	if len(thenCompare) == 0 {
+		return compare
+	}
+	return func(e1 types.T, e2 types.T) int {
+		if c := compare(e1, e2); c != 0 {
+			return c
+		}
+		for _, then := range thenCompare {
+			if c := then(e1, e2); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+}
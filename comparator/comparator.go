@@ -0,0 +1,199 @@
+package comparator
+
+import (
+	"errors"
+	"fmt"
+	"github.com/chinalhr/go-stream/internal/fields"
+	"github.com/chinalhr/go-stream/types"
+	"reflect"
+	"strings"
+	"time"
+)
+
+//Comparator Compares two elements, returns a negative number, zero, or a positive number as first is less than,
+//equal to, or greater than second. It has the signature of the compare function taken by the Stream operations,
+//such as Sorted, SortedStable, Max and Min, so it can be passed to them directly.
+type Comparator func(first types.T, second types.T) int
+
+//NaturalOrder Returns a Comparator comparing builtin ordered types (integers, floats, strings, bool and time.Time)
+//by their natural order. Elements of different kinds cause a panic.
+func NaturalOrder() Comparator {
+	return func(first types.T, second types.T) int {
+		return compareValues(reflect.ValueOf(first), reflect.ValueOf(second))
+	}
+}
+
+//Comparing Returns a Comparator comparing the keys extracted by keyExtractor in natural order.
+func Comparing(keyExtractor func(e types.T) types.T) Comparator {
+	return ComparingBy(keyExtractor, NaturalOrder())
+}
+
+//ComparingBy Returns a Comparator comparing the keys extracted by keyExtractor with keyComparator.
+func ComparingBy(keyExtractor func(e types.T) types.T, keyComparator Comparator) Comparator {
+	return func(first types.T, second types.T) int {
+		return keyComparator(keyExtractor(first), keyExtractor(second))
+	}
+}
+
+//ThenComparing Returns a Comparator that compares by c, and compares elements that c considers equal by other.
+func (c Comparator) ThenComparing(other Comparator) Comparator {
+	return func(first types.T, second types.T) int {
+		if res := c(first, second); res != 0 {
+			return res
+		}
+		return other(first, second)
+	}
+}
+
+//Reversed Returns a Comparator imposing the reverse order of c.
+func (c Comparator) Reversed() Comparator {
+	return func(first types.T, second types.T) int {
+		return c(second, first)
+	}
+}
+
+//NullsFirst Returns a Comparator that considers nil less than non-nil, non-nil elements are compared by c.
+//Both nil interfaces and nil pointers, maps, slices, channels and functions are considered nil.
+func NullsFirst(c Comparator) Comparator {
+	return nullsComparator(c, -1)
+}
+
+//NullsLast Returns a Comparator that considers nil greater than non-nil, non-nil elements are compared by c.
+func NullsLast(c Comparator) Comparator {
+	return nullsComparator(c, 1)
+}
+
+func nullsComparator(c Comparator, nilOrder int) Comparator {
+	return func(first types.T, second types.T) int {
+		firstNil, secondNil := fields.IsNil(reflect.ValueOf(first)), fields.IsNil(reflect.ValueOf(second))
+		switch {
+		case firstNil && secondNil:
+			return 0
+		case firstNil:
+			return nilOrder
+		case secondNil:
+			return -nilOrder
+		}
+		return c(first, second)
+	}
+}
+
+//ByField Returns a Comparator comparing the field of struct elements in natural order, the elements can be
+//structs or pointers to structs. name is a field name, or a dot separated path of nested fields such as
+//"Size.Weight", names are matched case-insensitively if no field matches exactly.
+//Unexported fields are supported.
+func ByField(name string) Comparator {
+	path := fields.Split(name)
+	return func(first types.T, second types.T) int {
+		return compareValues(fieldByPath(reflect.ValueOf(first), path), fieldByPath(reflect.ValueOf(second), path))
+	}
+}
+
+//ByFields Returns a Comparator comparing several fields in turn, spec is a comma separated list of field names
+//each optionally followed by asc or desc, for example "color asc, weight desc".
+//A malformed spec causes a panic.
+func ByFields(spec string) Comparator {
+	var c Comparator
+	for _, item := range strings.Split(spec, ",") {
+		words := strings.Fields(item)
+		if len(words) == 0 || len(words) > 2 {
+			panic(fmt.Errorf("invalid field order %q", strings.TrimSpace(item)))
+		}
+		fc := ByField(words[0])
+		if len(words) == 2 {
+			switch strings.ToLower(words[1]) {
+			case "asc":
+			case "desc":
+				fc = fc.Reversed()
+			default:
+				panic(fmt.Errorf("invalid field order %q", strings.TrimSpace(item)))
+			}
+		}
+		if c == nil {
+			c = fc
+		} else {
+			c = c.ThenComparing(fc)
+		}
+	}
+	return c
+}
+
+//fieldByPath Returns the field of v at path, a missing field causes a panic.
+func fieldByPath(v reflect.Value, path []string) reflect.Value {
+	field, err := fields.ByPath(v, path)
+	if err != nil {
+		panic(err)
+	}
+	return field
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+//compareValues Compare two values of builtin ordered types, values are compared through reflect so that
+//unexported fields can be compared as well.
+func compareValues(first reflect.Value, second reflect.Value) int {
+	for first.Kind() == reflect.Interface && !first.IsNil() {
+		first = first.Elem()
+	}
+	for second.Kind() == reflect.Interface && !second.IsNil() {
+		second = second.Elem()
+	}
+	if !first.IsValid() || !second.IsValid() || first.Kind() == reflect.Interface || second.Kind() == reflect.Interface {
+		panic(errors.New("natural order of nil value, use NullsFirst or NullsLast"))
+	}
+
+	if first.Type() == timeType && second.Type() == timeType {
+		firstTime, secondTime := timeOf(first), timeOf(second)
+		switch {
+		case firstTime.Before(secondTime):
+			return -1
+		case firstTime.After(secondTime):
+			return 1
+		}
+		return 0
+	}
+
+	switch {
+	case isInt(first.Kind()) && isInt(second.Kind()):
+		return order(first.Int() < second.Int(), first.Int() > second.Int())
+	case isUint(first.Kind()) && isUint(second.Kind()):
+		return order(first.Uint() < second.Uint(), first.Uint() > second.Uint())
+	case isFloat(first.Kind()) && isFloat(second.Kind()):
+		return order(first.Float() < second.Float(), first.Float() > second.Float())
+	case first.Kind() == reflect.String && second.Kind() == reflect.String:
+		return strings.Compare(first.String(), second.String())
+	case first.Kind() == reflect.Bool && second.Kind() == reflect.Bool:
+		return order(!first.Bool() && second.Bool(), first.Bool() && !second.Bool())
+	}
+	panic(fmt.Errorf("natural order of %s and %s is not defined", first.Type(), second.Type()))
+}
+
+func order(less bool, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+//timeOf Returns the time.Time of v, the fields of time.Time are unexported, so v needs to be exported.
+func timeOf(v reflect.Value) time.Time {
+	if !v.CanInterface() {
+		panic(errors.New("natural order of unexported time.Time field is not supported"))
+	}
+	return v.Interface().(time.Time)
+}
+
+func isInt(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func isUint(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uintptr
+}
+
+func isFloat(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
@@ -0,0 +1,132 @@
+package comparator
+
+import (
+	"github.com/chinalhr/go-stream"
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type size struct {
+	Weight int
+}
+
+type widget struct {
+	color string
+	size  size
+	Size  *size
+}
+
+func TestNaturalOrder(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		first  types.T
+		second types.T
+		actual int
+	}{
+		{name: "intCase", first: 1, second: 2, actual: -1},
+		{name: "int64Case", first: int64(2), second: int8(1), actual: 1},
+		{name: "uintCase", first: uint(2), second: uint(2), actual: 0},
+		{name: "floatCase", first: 1.5, second: 0.5, actual: 1},
+		{name: "stringCase", first: "a", second: "b", actual: -1},
+		{name: "boolCase", first: false, second: true, actual: -1},
+		{name: "timeCase", first: now.Add(time.Second), second: now, actual: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.actual, NaturalOrder()(test.first, test.second))
+		})
+	}
+
+	assert.Panics(t, func() {
+		NaturalOrder()(1, "a")
+	})
+	assert.Panics(t, func() {
+		NaturalOrder()(nil, 1)
+	})
+}
+
+func TestComparator(t *testing.T) {
+	widgets := []types.T{
+		widget{color: "yellow", size: size{4}},
+		widget{color: "red", size: size{3}},
+		widget{color: "yellow", size: size{2}},
+		widget{color: "blue", size: size{1}},
+	}
+
+	tests := []struct {
+		name       string
+		comparator Comparator
+		input      []types.T
+		actual     []types.T
+	}{
+		{
+			name: "comparingCase",
+			comparator: Comparing(func(e types.T) types.T {
+				return e.(widget).size.Weight
+			}),
+			input:  widgets,
+			actual: []types.T{widgets[3], widgets[2], widgets[1], widgets[0]},
+		},
+		{
+			name:       "thenComparingCase",
+			comparator: ByField("color").ThenComparing(ByField("size.Weight")),
+			input:      widgets,
+			actual:     []types.T{widgets[3], widgets[1], widgets[2], widgets[0]},
+		},
+		{
+			name:       "reversedCase",
+			comparator: ByField("color").Reversed(),
+			input:      widgets,
+			actual:     []types.T{widgets[0], widgets[2], widgets[1], widgets[3]},
+		},
+		{
+			name:       "byFieldsCase",
+			comparator: ByFields("color asc, size.weight desc"),
+			input:      widgets,
+			actual:     []types.T{widgets[3], widgets[1], widgets[0], widgets[2]},
+		},
+		{
+			name:       "nullsFirstCase",
+			comparator: NullsFirst(NaturalOrder()),
+			input:      []types.T{2, nil, 1},
+			actual:     []types.T{nil, 1, 2},
+		},
+		{
+			name:       "nullsLastCase",
+			comparator: NullsLast(NaturalOrder()),
+			input:      []types.T{2, nil, 1},
+			actual:     []types.T{1, 2, nil},
+		},
+		{
+			name:       "pointerFieldCase",
+			comparator: ByField("Size.Weight"),
+			input:      []types.T{&widget{Size: &size{2}}, &widget{Size: &size{1}}},
+			actual:     []types.T{&widget{Size: &size{1}}, &widget{Size: &size{2}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := stream.OfSlice(test.input).
+				SortedStable(test.comparator).
+				ToSlice()
+			assert.Equal(t, test.actual, result)
+		})
+	}
+}
+
+func TestByFields_Panic(t *testing.T) {
+	assert.Panics(t, func() {
+		ByFields("color up")
+	})
+	assert.Panics(t, func() {
+		ByFields("color,,weight")
+	})
+	assert.Panics(t, func() {
+		ByField("missing")(widget{}, widget{})
+	})
+}
@@ -0,0 +1,414 @@
+package stream
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/chinalhr/go-stream/types"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//CSVParseError Is the error of a CSV record that can not be parsed or bound, Row is the 1-based line of the
+//input. Column is the 1-based column of the field that can not be converted, or the 1-based byte column reported
+//by encoding/csv for a malformed record.
+type CSVParseError struct {
+	Row    int
+	Column int
+	Err    error
+}
+
+func (e *CSVParseError) Error() string {
+	return fmt.Sprintf("csv: row %d, column %d: %v", e.Row, e.Column, e.Err)
+}
+
+func (e *CSVParseError) Unwrap() error {
+	return e.Err
+}
+
+//WithComma Returns an IOOption setting the field delimiter of CSV, ',' by default.
+func WithComma(comma rune) IOOption {
+	return func(o *ioOptions) {
+		o.comma = comma
+	}
+}
+
+//WithHeader Returns an IOOption naming the CSV columns by columns, the first record is then read as data
+//instead of as the header.
+func WithHeader(columns ...string) IOOption {
+	return func(o *ioOptions) {
+		o.header = columns
+	}
+}
+
+//WithTimeLayout Returns an IOOption setting the layout that CSV time.Time fields are parsed and formatted by,
+//time.RFC3339 by default.
+func WithTimeLayout(layout string) IOOption {
+	return func(o *ioOptions) {
+		o.timeLayout = layout
+	}
+}
+
+//WithStruct Returns an IOOption binding CSV records into structs, newElem returns a pointer to a new struct and
+//the element is the struct it points to. OfFile also decodes JSON records into the values of newElem.
+func WithStruct(newElem func() types.T) IOOption {
+	return func(o *ioOptions) {
+		o.newElem = newElem
+	}
+}
+
+//OfCSV Return a sequential Stream of the records of the CSV read from r. The columns are named by the first
+//record, or by WithHeader. A record is a map[string]string keyed by column, or a struct with WithStruct, whose
+//fields are bound to the columns named by their `csv:"col"` tag, or by their name if untagged, `csv:"-"` fields
+//are ignored. String, int, uint, float, bool and time.Time fields are converted from the text of the column, an
+//empty text leaves the zero value. A record that can not be parsed or converted fails the Stream with a
+//*CSVParseError returned by Err, unless SkipBadRecords is given. r is read lazily and closed like OfLines.
+func OfCSV(r io.Reader, opts ...IOOption) Stream {
+	o := newIOOptions(opts)
+	r = openReader(r, o)
+	reader := csv.NewReader(r)
+	reader.Comma = o.comma
+	pipeline := newPipeline(&csvIterator{
+		reader:  reader,
+		source:  r,
+		options: o,
+		header:  o.header,
+	})
+	return Stream{pipeline}
+}
+
+//ToCSV Write the elements to w as CSV records, preceded by a header row of columns. The elements can be
+//map[string]T or structs, or pointers to structs, bound to columns like OfCSV. If columns is empty, the columns
+//are the bound fields of a struct element, or the sorted keys of a map element, taken from the first element.
+//time.Time values are formatted by WithTimeLayout, nil values are written as empty fields.
+//Returns the first error of writing or evaluating the Stream.
+func (s Stream) ToCSV(w io.Writer, columns []string, opts ...IOOption) error {
+	o := newIOOptions(opts)
+	out, flush := openWriter(w, o)
+	writer := csv.NewWriter(out)
+	writer.Comma = o.comma
+	headerWritten := false
+	writeHeader := func() error {
+		headerWritten = true
+		return writer.Write(columns)
+	}
+	err := writeEach(s.p, func(e types.T) error {
+		if !headerWritten {
+			if len(columns) == 0 {
+				columns = csvColumns(e)
+			}
+			if len(columns) == 0 {
+				return fmt.Errorf("csv: can not derive the columns of %T", e)
+			}
+			if err := writeHeader(); err != nil {
+				return err
+			}
+		}
+		record, err := csvRecord(e, columns, o.timeLayout)
+		if err != nil {
+			return err
+		}
+		return writer.Write(record)
+	})
+	if err == nil && !headerWritten && len(columns) > 0 {
+		err = writeHeader()
+	}
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	return closeWriter(err, flush)
+}
+
+//csvIterator A general type iterator reading records from a csv.Reader, the header is read by the first HasNext.
+type csvIterator struct {
+	iteratorInfiniteBaseInfo
+	reader  *csv.Reader
+	source  io.Reader
+	options *ioOptions
+	header  []string
+	binding *csvBinding
+	next    types.T
+	hasNext bool
+	done    bool
+	closed  bool
+	err     error
+}
+
+func (it *csvIterator) HasNext() bool {
+	if it.hasNext {
+		return true
+	}
+	if it.done {
+		return false
+	}
+	if it.header == nil && !it.readHeader() {
+		return false
+	}
+	for {
+		record, err := it.reader.Read()
+		if err == io.EOF {
+			it.done = true
+			return false
+		}
+		var e types.T
+		if err == nil {
+			e, err = it.decode(record)
+		}
+		if err != nil {
+			if it.options.skipBad && isBadCSVRecord(err) {
+				continue
+			}
+			it.fail(err)
+			return false
+		}
+		it.next, it.hasNext = e, true
+		return true
+	}
+}
+
+func (it *csvIterator) readHeader() bool {
+	header, err := it.reader.Read()
+	if err == io.EOF {
+		it.done = true
+		return false
+	}
+	if err != nil {
+		it.fail(err)
+		return false
+	}
+	it.header = append([]string(nil), header...)
+	return true
+}
+
+//decode Returns the record as a map keyed by the header, or bound into a struct.
+func (it *csvIterator) decode(record []string) (types.T, error) {
+	if it.options.newElem == nil {
+		m := make(map[string]string, len(it.header))
+		for i, column := range it.header {
+			if i < len(record) {
+				m[column] = record[i]
+			}
+		}
+		return m, nil
+	}
+	ptr := reflect.ValueOf(it.options.newElem())
+	if it.binding == nil {
+		it.binding = newCSVBinding(ptr.Elem().Type(), it.header)
+	}
+	v := ptr.Elem()
+	for i, field := range it.binding.fields {
+		if field == nil || i >= len(record) || record[i] == "" {
+			continue
+		}
+		if err := setCSVField(v.FieldByIndex(field), record[i], it.options.timeLayout); err != nil {
+			row, _ := it.reader.FieldPos(i)
+			return nil, &CSVParseError{Row: row, Column: i + 1, Err: fmt.Errorf("column %q: %w", it.header[i], err)}
+		}
+	}
+	return v.Interface(), nil
+}
+
+//isBadCSVRecord Returns true if err is caused by the content of a record rather than by reading the input.
+func isBadCSVRecord(err error) bool {
+	var parseErr *csv.ParseError
+	var bindErr *CSVParseError
+	return errors.As(err, &parseErr) || errors.As(err, &bindErr)
+}
+
+func (it *csvIterator) fail(err error) {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		err = &CSVParseError{Row: parseErr.Line, Column: parseErr.Column, Err: parseErr.Err}
+	}
+	it.err = err
+	it.done = true
+}
+
+func (it *csvIterator) Next() types.T {
+	it.HasNext()
+	e := it.next
+	it.next, it.hasNext = nil, false
+	return e
+}
+
+func (it *csvIterator) Err() error {
+	return it.err
+}
+
+func (it *csvIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	it.done = true
+	if closer, ok := it.source.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+//csvBinding The struct fields bound to the columns of a header, fields[i] is the index of the field bound to the
+//column i, or nil if no field is bound to it.
+type csvBinding struct {
+	fields [][]int
+}
+
+func newCSVBinding(t reflect.Type, header []string) *csvBinding {
+	names := csvFieldNames(t)
+	binding := &csvBinding{fields: make([][]int, len(header))}
+	for i, column := range header {
+		if index, ok := names[column]; ok {
+			binding.fields[i] = index
+		}
+	}
+	return binding
+}
+
+//csvFieldNames Returns the index of the bound fields of the struct type t by column name.
+func csvFieldNames(t reflect.Type) map[string][]int {
+	names := make(map[string][]int)
+	for _, field := range csvStructFields(t) {
+		names[field.name] = field.index
+	}
+	return names
+}
+
+type csvStructField struct {
+	name  string
+	index []int
+}
+
+func csvStructFields(t reflect.Type) []csvStructField {
+	fields := make([]csvStructField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Tag.Get("csv")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, csvStructField{name: name, index: field.Index})
+	}
+	return fields
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+//setCSVField Convert text to the type of field and set it.
+func setCSVField(field reflect.Value, text string, timeLayout string) error {
+	if field.Type() == timeType {
+		t, err := time.Parse(timeLayout, text)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(strings.TrimSpace(text), 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(strings.TrimSpace(text), 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(text), field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %v", field.Type())
+	}
+	return nil
+}
+
+//csvColumns Returns the columns of e, the bound fields of a struct or the sorted keys of a map.
+func csvColumns(e types.T) []string {
+	v := reflect.Indirect(reflect.ValueOf(e))
+	columns := make([]string, 0)
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, field := range csvStructFields(v.Type()) {
+			columns = append(columns, field.name)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		for _, key := range v.MapKeys() {
+			columns = append(columns, key.String())
+		}
+		sort.Strings(columns)
+	}
+	return columns
+}
+
+//csvRecord Returns the texts of the columns of e.
+func csvRecord(e types.T, columns []string, timeLayout string) ([]string, error) {
+	v := reflect.Indirect(reflect.ValueOf(e))
+	var names map[string][]int
+	switch {
+	case v.Kind() == reflect.Struct:
+		names = csvFieldNames(v.Type())
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+	default:
+		return nil, fmt.Errorf("csv: unsupported element type %T", e)
+	}
+	record := make([]string, 0, len(columns))
+	for _, column := range columns {
+		var field reflect.Value
+		if names != nil {
+			index, ok := names[column]
+			if !ok {
+				return nil, fmt.Errorf("csv: %T has no column %q", e, column)
+			}
+			field = v.FieldByIndex(index)
+		} else {
+			field = v.MapIndex(reflect.ValueOf(column).Convert(v.Type().Key()))
+		}
+		record = append(record, csvText(field, timeLayout))
+	}
+	return record, nil
+}
+
+//csvText Returns the text of a field, invalid and nil values are empty.
+func csvText(field reflect.Value, timeLayout string) string {
+	for field.IsValid() && (field.Kind() == reflect.Interface || field.Kind() == reflect.Ptr) {
+		if field.IsNil() {
+			return ""
+		}
+		field = field.Elem()
+	}
+	if !field.IsValid() {
+		return ""
+	}
+	if t, ok := field.Interface().(time.Time); ok {
+		return t.Format(timeLayout)
+	}
+	return fmt.Sprint(field.Interface())
+}
@@ -0,0 +1,117 @@
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+type csvTrade struct {
+	Symbol string    `csv:"symbol"`
+	Price  float64   `csv:"price"`
+	Volume int       `csv:"volume"`
+	Open   bool      `csv:"open"`
+	At     time.Time `csv:"at"`
+	Note   string    `csv:"-"`
+}
+
+func newCSVTrade() types.T {
+	return &csvTrade{}
+}
+
+func TestOfCSV(t *testing.T) {
+	at := time.Date(2022, 3, 1, 9, 30, 0, 0, time.UTC)
+	text := "symbol,price,volume,open,at\n" +
+		"AAA,1.5,100,true,2022-03-01T09:30:00Z\n" +
+		"BBB,2,,false,\n"
+
+	t.Run("mapCase", func(t *testing.T) {
+		r := newTrackingReader(text)
+		s := OfCSV(r)
+		assert.Equal(t, []types.T{
+			map[string]string{"symbol": "AAA", "price": "1.5", "volume": "100", "open": "true", "at": "2022-03-01T09:30:00Z"},
+			map[string]string{"symbol": "BBB", "price": "2", "volume": "", "open": "false", "at": ""},
+		}, s.ToSlice())
+		assert.NoError(t, s.Err())
+		assert.True(t, r.closed)
+	})
+
+	t.Run("structCase", func(t *testing.T) {
+		s := OfCSV(strings.NewReader(text), WithStruct(newCSVTrade))
+		assert.Equal(t, []types.T{
+			csvTrade{Symbol: "AAA", Price: 1.5, Volume: 100, Open: true, At: at},
+			csvTrade{Symbol: "BBB", Price: 2},
+		}, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+
+	t.Run("headerOptionCase", func(t *testing.T) {
+		s := OfCSV(strings.NewReader("AAA;7\nBBB;8\n"), WithComma(';'), WithHeader("symbol", "volume"),
+			WithStruct(newCSVTrade))
+		assert.Equal(t, []types.T{csvTrade{Symbol: "AAA", Volume: 7}, csvTrade{Symbol: "BBB", Volume: 8}}, s.ToSlice())
+	})
+
+	t.Run("convertErrCase", func(t *testing.T) {
+		s := OfCSV(strings.NewReader("symbol,volume\nAAA,1\nBBB,many\nCCC,3\n"), WithStruct(newCSVTrade))
+		assert.Equal(t, []types.T{csvTrade{Symbol: "AAA", Volume: 1}}, s.ToSlice())
+		var parseErr *CSVParseError
+		assert.True(t, errors.As(s.Err(), &parseErr))
+		assert.Equal(t, 3, parseErr.Row)
+		assert.Equal(t, 2, parseErr.Column)
+	})
+
+	t.Run("parseErrCase", func(t *testing.T) {
+		s := OfCSV(strings.NewReader("symbol,volume\nAAA,1\nBBB\n"))
+		assert.Equal(t, 1, s.Count())
+		var parseErr *CSVParseError
+		assert.True(t, errors.As(s.Err(), &parseErr))
+		assert.Equal(t, 3, parseErr.Row)
+	})
+
+	t.Run("skipBadRecordCase", func(t *testing.T) {
+		s := OfCSV(strings.NewReader("symbol,volume\nAAA,1\nBBB\nCCC,x\nDDD,4\n"), WithStruct(newCSVTrade),
+			SkipBadRecords())
+		assert.Equal(t, []types.T{csvTrade{Symbol: "AAA", Volume: 1}, csvTrade{Symbol: "DDD", Volume: 4}}, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+}
+
+func TestStream_ToCSV(t *testing.T) {
+	at := time.Date(2022, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	t.Run("structCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := OfElements(csvTrade{Symbol: "AAA", Price: 1.5, Volume: 100, Open: true, At: at, Note: "x"},
+			&csvTrade{Symbol: "B,B"}).ToCSV(&buf, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "symbol,price,volume,open,at\n"+
+			"AAA,1.5,100,true,2022-03-01T09:30:00Z\n"+
+			"\"B,B\",0,0,false,0001-01-01T00:00:00Z\n", buf.String())
+
+		actual := OfCSV(&buf, WithStruct(newCSVTrade)).ToSlice()
+		assert.Equal(t, csvTrade{Symbol: "AAA", Price: 1.5, Volume: 100, Open: true, At: at}, actual[0])
+	})
+
+	t.Run("mapCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := OfElements(map[string]interface{}{"b": 1, "a": "x"}, map[string]interface{}{"a": nil}).
+			ToCSV(&buf, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "a,b\nx,1\n,\n", buf.String())
+	})
+
+	t.Run("columnsCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := OfElements().ToCSV(&buf, []string{"symbol", "volume"})
+		assert.NoError(t, err)
+		assert.Equal(t, "symbol,volume\n", buf.String())
+
+		buf.Reset()
+		err = OfElements(csvTrade{Symbol: "AAA"}).ToCSV(&buf, []string{"volume", "missing"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,98 @@
+package stream
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//OfFile Return a sequential Stream of the records of the file at path, decoded by the format named by the
+//extension of path: OfJSONLines for ".jsonl" and ".ndjson", OfJSONArray for ".json", OfCSV for ".csv", OfGob for
+//".gob", and OfLines otherwise. WithStruct gives the newElem of JSON and CSV records, gob files are read as
+//interface values like ToGob writes them. The file is decompressed by gzip if path ends with ".gz" or WithGzip is
+//given, otherwise if it starts with the gzip magic bytes. The file is opened by the first element pulled and closed
+//once the Stream has been evaluated, an error of opening or reading it is returned by Err.
+func OfFile(path string, opts ...IOOption) Stream {
+	o := newIOOptions(opts)
+	format, gz := fileFormat(path)
+	r := &gzipReader{r: &lazyFile{path: path}, detect: !gz && !o.gzip}
+	opts = append(opts[:len(opts):len(opts)], func(o *ioOptions) {
+		o.gzip = false
+	})
+	switch format {
+	case ".jsonl", ".ndjson":
+		return OfJSONLines(r, o.newElem, opts...)
+	case ".json":
+		return OfJSONArray(r, o.newElem, opts...)
+	case ".csv":
+		return OfCSV(r, opts...)
+	case ".gob":
+		return OfGob(r, nil, opts...)
+	}
+	return OfLines(r, opts...)
+}
+
+//ToFile Write the elements to the file at path, encoded by the format named by the extension of path:
+//ToJSONLines for ".jsonl" and ".ndjson", ToJSONArray for ".json", ToCSV with the columns of the first element for
+//".csv", ToGob for ".gob", and ToLines otherwise. The output is compressed by gzip if path ends with ".gz" or if
+//WithGzip is given. The file is created or truncated, and closed once the elements are written.
+//Returns the first error of writing the file or evaluating the Stream.
+func (s Stream) ToFile(path string, opts ...IOOption) error {
+	format, gz := fileFormat(path)
+	if gz {
+		opts = append(opts[:len(opts):len(opts)], WithGzip())
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case ".jsonl", ".ndjson":
+		err = s.ToJSONLines(file, opts...)
+	case ".json":
+		err = s.ToJSONArray(file, opts...)
+	case ".csv":
+		err = s.ToCSV(file, nil, opts...)
+	case ".gob":
+		err = s.ToGob(file, opts...)
+	default:
+		err = s.ToLines(file, opts...)
+	}
+	return closeWriter(err, file.Close)
+}
+
+//fileFormat Returns the lower case extension of path naming its format, and whether path ends with ".gz".
+func fileFormat(path string) (string, bool) {
+	path = strings.ToLower(path)
+	gz := strings.HasSuffix(path, ".gz")
+	if gz {
+		path = strings.TrimSuffix(path, ".gz")
+	}
+	return filepath.Ext(path), gz
+}
+
+//lazyFile Opens the file at path by the first Read.
+type lazyFile struct {
+	path string
+	file *os.File
+	err  error
+}
+
+func (f *lazyFile) Read(p []byte) (int, error) {
+	if f.file == nil && f.err == nil {
+		f.file, f.err = os.Open(f.path)
+	}
+	if f.err != nil {
+		return 0, f.err
+	}
+	return f.file.Read(p)
+}
+
+func (f *lazyFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -0,0 +1,120 @@
+package stream
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func gunzip(t *testing.T, data []byte) string {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+	text, err := io.ReadAll(zr)
+	assert.NoError(t, err)
+	return string(text)
+}
+
+func TestGzip(t *testing.T) {
+	t.Run("linesCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, OfElements("a", 2, "c").ToLines(&buf, WithGzip()))
+		assert.Equal(t, "a\n2\nc\n", gunzip(t, buf.Bytes()))
+		assert.Equal(t, []types.T{"a", "2", "c"}, OfLines(&buf, WithGzip()).ToSlice())
+	})
+
+	t.Run("jsonLinesCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, OfElements(jsonEvent{1, "a"}).ToJSONLines(&buf, WithGzip()))
+		assert.Equal(t, []types.T{jsonEvent{1, "a"}}, OfJSONLines(&buf, newJSONEvent, WithGzip()).ToSlice())
+	})
+
+	t.Run("jsonArrayCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, OfElements(jsonEvent{1, "a"}, jsonEvent{2, "b"}).ToJSONArray(&buf, WithGzip()))
+		assert.Equal(t, "[\n{\"id\":1,\"name\":\"a\"},\n{\"id\":2,\"name\":\"b\"}\n]\n", gunzip(t, buf.Bytes()))
+		actual := OfJSONArray(&buf, newJSONEvent, WithGzip()).ToSlice()
+		assert.Equal(t, []types.T{jsonEvent{1, "a"}, jsonEvent{2, "b"}}, actual)
+	})
+
+	t.Run("csvCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, OfElements(csvTrade{Symbol: "AAA", Volume: 1}).ToCSV(&buf, []string{"symbol", "volume"},
+			WithGzip()))
+		actual := OfCSV(&buf, WithGzip(), WithStruct(newCSVTrade)).ToSlice()
+		assert.Equal(t, []types.T{csvTrade{Symbol: "AAA", Volume: 1}}, actual)
+	})
+
+	t.Run("framesCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, OfElements(1, "b").ToFrames(&buf, EncodeTyped, WithGzip()))
+		assert.Equal(t, []types.T{1, "b"}, OfFrames(&buf, DecodeTyped, WithGzip()).ToSlice())
+	})
+
+	t.Run("notGzipCase", func(t *testing.T) {
+		s := OfLines(newTrackingReader("plain text that is not compressed\n"), WithGzip())
+		assert.Equal(t, 0, s.Count())
+		assert.Equal(t, gzip.ErrHeader, s.Err())
+	})
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	elements := func() Stream {
+		return OfElements(jsonEvent{1, "a"}, jsonEvent{2, "b"})
+	}
+
+	tests := []struct {
+		name string
+		opts []IOOption
+	}{
+		{name: "events.jsonl", opts: []IOOption{WithStruct(newJSONEvent)}},
+		{name: "events.ndjson.gz", opts: []IOOption{WithStruct(newJSONEvent)}},
+		{name: "events.json", opts: []IOOption{WithStruct(newJSONEvent)}},
+		{name: "events.JSON.GZ", opts: []IOOption{WithStruct(newJSONEvent)}},
+		{name: "events.gob.gz"},
+	}
+	RegisterType(jsonEvent{})
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, test.name)
+			assert.NoError(t, elements().ToFile(path))
+			s := OfFile(path, test.opts...)
+			assert.Equal(t, []types.T{jsonEvent{1, "a"}, jsonEvent{2, "b"}}, s.ToSlice())
+			assert.NoError(t, s.Err())
+		})
+	}
+
+	t.Run("csvCase", func(t *testing.T) {
+		path := filepath.Join(dir, "events.csv.gz")
+		assert.NoError(t, elements().ToFile(path))
+		actual := OfFile(path).ToSlice()
+		assert.Equal(t, []types.T{
+			map[string]string{"ID": "1", "Name": "a"},
+			map[string]string{"ID": "2", "Name": "b"},
+		}, actual)
+	})
+
+	t.Run("detectGzipCase", func(t *testing.T) {
+		path := filepath.Join(dir, "lines.log")
+		assert.NoError(t, OfElements("x", "y").ToFile(path, WithGzip()))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "x\ny\n", gunzip(t, data))
+		assert.Equal(t, []types.T{"x", "y"}, OfFile(path).ToSlice())
+
+		plain := filepath.Join(dir, "plain.txt")
+		assert.NoError(t, OfElements("p").ToFile(plain))
+		assert.Equal(t, []types.T{"p"}, OfFile(plain).ToSlice())
+	})
+
+	t.Run("missingCase", func(t *testing.T) {
+		s := OfFile(filepath.Join(dir, "missing.txt"))
+		assert.Equal(t, 0, s.Count())
+		assert.True(t, os.IsNotExist(s.Err()))
+	})
+}
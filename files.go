@@ -0,0 +1,282 @@
+package stream
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/chinalhr/go-stream/types"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//ManifestEntry Is a file written by ToFiles, Sequence is the 0-based index of the file among the files of its
+//partition, Records and Bytes are the count of the elements and of the encoded bytes in the file.
+type ManifestEntry struct {
+	Partition string
+	Sequence  int
+	Path      string
+	Records   int
+	Bytes     int64
+}
+
+//FilesOption Configures ToFiles.
+type FilesOption func(o *filesOptions)
+
+//filesOptions
+//maxBytes and maxRecords rotate the file of a partition once it holds as many bytes or elements, 0 never rotates.
+//fileName returns the path of a file relative to the output directory.
+type filesOptions struct {
+	maxBytes   int64
+	maxRecords int
+	fileName   func(partition string, sequence int) string
+}
+
+//RotateBySize Returns a FilesOption starting a new file of a partition once the current one holds n bytes or more.
+func RotateBySize(n int64) FilesOption {
+	if n <= 0 {
+		panic(errors.New("rotation size must be positive"))
+	}
+	return func(o *filesOptions) {
+		o.maxBytes = n
+	}
+}
+
+//RotateByCount Returns a FilesOption starting a new file of a partition once the current one holds n elements.
+func RotateByCount(n int) FilesOption {
+	if n <= 0 {
+		panic(errors.New("rotation count must be positive"))
+	}
+	return func(o *filesOptions) {
+		o.maxRecords = n
+	}
+}
+
+//WithFileName Returns a FilesOption naming the files by fileName, which returns the path of the file of the
+//partition with the sequence relative to the output directory. The default name is "<partition>-<sequence>",
+//such as "2022-05-01-00000".
+func WithFileName(fileName func(partition string, sequence int) string) FilesOption {
+	return func(o *filesOptions) {
+		o.fileName = fileName
+	}
+}
+
+//ToFiles Write the elements to files in dir, one or more files per partition key returned by partitionFn, the
+//elements of a file are encoded by codec, such as JSONCodec or GobCodec. A partition key can contain '/' to
+//write its files into subdirectories of dir, but must not lead out of dir. The files of a partition are rotated
+//by RotateBySize and RotateByCount. The files are written as temp files next to their final paths, which are
+//renamed to the final paths once all elements are written, so a partial output is never visible under the final
+//paths. If encoding, writing or evaluating the Stream fails, or two files resolve to the same path, the temp files
+//are removed and the error is returned. If renaming a file fails, the files already renamed are removed and the
+//regular files they replaced are restored, a crash during the renames can still leave part of them renamed.
+//Returns the manifest of the files written, sorted by partition and sequence.
+func (s Stream) ToFiles(dir string, partitionFn func(e types.T) string, codec Codec,
+	opts ...FilesOption) ([]ManifestEntry, error) {
+	o := &filesOptions{
+		fileName: func(partition string, sequence int) string {
+			return fmt.Sprintf("%s-%05d", partition, sequence)
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	w := &filesWriter{
+		dir:        dir,
+		codec:      codec,
+		options:    o,
+		partitions: make(map[string]*partitionFile),
+		sequences:  make(map[string]int),
+		paths:      make(map[string]string),
+	}
+	err := writeEach(s.p, func(e types.T) error {
+		return w.write(partitionFn(e), e)
+	})
+	if err != nil {
+		w.abort()
+		return nil, err
+	}
+	return w.commit()
+}
+
+//partitionFile The temp file being written for a partition.
+type partitionFile struct {
+	entry   ManifestEntry
+	temp    *os.File
+	buf     *bufio.Writer
+	counter *countingWriter
+	enc     Encoder
+}
+
+//countingWriter Counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+//filesWriter Writes the partitions of ToFiles, partitions holds the open file of every partition, sequences the
+//sequence of the next file of every partition, paths the partition of every final path, and finished the files
+//that are completely written.
+type filesWriter struct {
+	dir        string
+	codec      Codec
+	options    *filesOptions
+	partitions map[string]*partitionFile
+	sequences  map[string]int
+	paths      map[string]string
+	finished   []*partitionFile
+}
+
+func (w *filesWriter) write(partition string, e types.T) error {
+	file, ok := w.partitions[partition]
+	if !ok {
+		var err error
+		if file, err = w.open(partition); err != nil {
+			return err
+		}
+	}
+	if err := file.enc.Encode(e); err != nil {
+		return err
+	}
+	file.entry.Records++
+	if (w.options.maxRecords > 0 && file.entry.Records >= w.options.maxRecords) ||
+		(w.options.maxBytes > 0 && file.counter.n >= w.options.maxBytes) {
+		return w.finish(file)
+	}
+	return nil
+}
+
+//open Create the temp file of the next file of partition.
+func (w *filesWriter) open(partition string) (*partitionFile, error) {
+	sequence := w.sequences[partition]
+	name := filepath.Clean(filepath.FromSlash(w.options.fileName(partition, sequence)))
+	if partition == "" || filepath.IsAbs(name) || name == ".." ||
+		strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("invalid partition %q", partition)
+	}
+	path := filepath.Join(w.dir, name)
+	if other, ok := w.paths[path]; ok {
+		return nil, fmt.Errorf("partitions %q and %q write the same file %s", other, partition, path)
+	}
+	w.paths[path] = partition
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(temp)
+	counter := &countingWriter{w: buf}
+	file := &partitionFile{
+		entry:   ManifestEntry{Partition: partition, Sequence: sequence, Path: path},
+		temp:    temp,
+		buf:     buf,
+		counter: counter,
+		enc:     w.codec.NewEncoder(counter),
+	}
+	w.partitions[partition] = file
+	w.sequences[partition] = sequence + 1
+	return file, nil
+}
+
+//finish Flush, sync and close the temp file of a partition, the next element of the partition opens a new file.
+func (w *filesWriter) finish(file *partitionFile) error {
+	delete(w.partitions, file.entry.Partition)
+	w.finished = append(w.finished, file)
+	file.entry.Bytes = file.counter.n
+	err := file.buf.Flush()
+	if err == nil {
+		err = file.temp.Sync()
+	}
+	if closeErr := file.temp.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+//commit Finish the open files and rename all temp files to their final paths, the renames are rolled back if one
+//of them fails.
+func (w *filesWriter) commit() ([]ManifestEntry, error) {
+	for _, file := range w.partitions {
+		if err := w.finish(file); err != nil {
+			w.abort()
+			return nil, err
+		}
+	}
+	sort.Slice(w.finished, func(i, j int) bool {
+		a, b := w.finished[i].entry, w.finished[j].entry
+		if a.Partition != b.Partition {
+			return a.Partition < b.Partition
+		}
+		return a.Sequence < b.Sequence
+	})
+	backups := make([]string, 0, len(w.finished))
+	for _, file := range w.finished {
+		backup, err := replaceFile(file.temp.Name(), file.entry.Path)
+		if err != nil {
+			for i := len(backups) - 1; i >= 0; i-- {
+				path := w.finished[i].entry.Path
+				_ = os.Remove(path)
+				if backups[i] != "" {
+					_ = os.Rename(backups[i], path)
+				}
+			}
+			w.abort()
+			return nil, err
+		}
+		backups = append(backups, backup)
+	}
+	manifest := make([]ManifestEntry, 0, len(w.finished))
+	for i, file := range w.finished {
+		if backups[i] != "" {
+			_ = os.Remove(backups[i])
+		}
+		manifest = append(manifest, file.entry)
+	}
+	return manifest, nil
+}
+
+//replaceFile Rename temp to path, a regular file at path is first moved to a backup next to it, which is restored
+//if the rename fails. Returns the path of the backup, or "" if there was no file to replace.
+func replaceFile(temp, path string) (string, error) {
+	backup := ""
+	if info, err := os.Lstat(path); err == nil && info.Mode().IsRegular() {
+		f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".bak-*")
+		if err != nil {
+			return "", err
+		}
+		backup = f.Name()
+		_ = f.Close()
+		if err := os.Rename(path, backup); err != nil {
+			_ = os.Remove(backup)
+			return "", err
+		}
+	}
+	if err := os.Rename(temp, path); err != nil {
+		if backup != "" {
+			_ = os.Rename(backup, path)
+		}
+		return "", err
+	}
+	return backup, nil
+}
+
+//abort Close and remove all temp files.
+func (w *filesWriter) abort() {
+	for _, file := range w.partitions {
+		_ = file.temp.Close()
+		_ = os.Remove(file.temp.Name())
+	}
+	for _, file := range w.finished {
+		_ = os.Remove(file.temp.Name())
+	}
+	w.partitions, w.finished = nil, nil
+}
@@ -0,0 +1,169 @@
+package stream
+
+import (
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func byEventName(e types.T) string {
+	return e.(jsonEvent).Name
+}
+
+func dirNames(t *testing.T, dir string) []string {
+	var names []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	assert.NoError(t, err)
+	return names
+}
+
+func TestToFiles(t *testing.T) {
+	events := func() Stream {
+		return OfElements(jsonEvent{1, "a"}, jsonEvent{2, "b"}, jsonEvent{3, "a"}, jsonEvent{4, "a"})
+	}
+
+	t.Run("partitionCase", func(t *testing.T) {
+		dir := t.TempDir()
+		manifest, err := events().ToFiles(dir, byEventName, JSONCodec(newJSONEvent))
+		assert.NoError(t, err)
+		assert.Equal(t, []ManifestEntry{
+			{Partition: "a", Path: filepath.Join(dir, "a-00000"), Records: 3, Bytes: 60},
+			{Partition: "b", Path: filepath.Join(dir, "b-00000"), Records: 1, Bytes: 20},
+		}, manifest)
+		assert.Equal(t, []string{"a-00000", "b-00000"}, dirNames(t, dir))
+
+		actual := OfJSONLines(newTrackingReader(readFile(t, manifest[0].Path)), newJSONEvent).ToSlice()
+		assert.Equal(t, []types.T{jsonEvent{1, "a"}, jsonEvent{3, "a"}, jsonEvent{4, "a"}}, actual)
+	})
+
+	t.Run("rotateByCountCase", func(t *testing.T) {
+		dir := t.TempDir()
+		manifest, err := events().ToFiles(dir, byEventName, JSONCodec(newJSONEvent), RotateByCount(2))
+		assert.NoError(t, err)
+		var records []int
+		for _, entry := range manifest {
+			records = append(records, entry.Records)
+		}
+		assert.Equal(t, []int{2, 1, 1}, records)
+		assert.Equal(t, []string{"a-00000", "a-00001", "b-00000"}, dirNames(t, dir))
+	})
+
+	t.Run("rotateBySizeCase", func(t *testing.T) {
+		dir := t.TempDir()
+		manifest, err := events().ToFiles(dir, byEventName, JSONCodec(newJSONEvent), RotateBySize(20))
+		assert.NoError(t, err)
+		assert.Equal(t, 4, len(manifest))
+		for _, entry := range manifest {
+			assert.Equal(t, 1, entry.Records)
+			assert.Equal(t, int64(20), entry.Bytes)
+		}
+	})
+
+	t.Run("fileNameCase", func(t *testing.T) {
+		RegisterType(jsonEvent{})
+		dir := t.TempDir()
+		_, err := events().ToFiles(dir, func(e types.T) string {
+			return "tenant=" + byEventName(e)
+		}, GobCodec(), WithFileName(func(partition string, sequence int) string {
+			return partition + "/part-" + string(rune('0'+sequence)) + ".gob"
+		}), RotateByCount(2))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"tenant=a/part-0.gob", "tenant=a/part-1.gob", "tenant=b/part-0.gob"},
+			dirNames(t, dir))
+
+		actual := OfFile(filepath.Join(dir, "tenant=a", "part-1.gob")).ToSlice()
+		assert.Equal(t, []types.T{jsonEvent{4, "a"}}, actual)
+	})
+
+	t.Run("parallelCase", func(t *testing.T) {
+		dir := t.TempDir()
+		manifest, err := Range(0, 1000, 1).Parallel(4).ToFiles(dir, func(e types.T) string {
+			return string(rune('a' + e.(int)%3))
+		}, JSONCodec(nil), RotateByCount(100))
+		assert.NoError(t, err)
+		total := 0
+		for _, entry := range manifest {
+			total += entry.Records
+		}
+		assert.Equal(t, 1000, total)
+		assert.Equal(t, 12, len(dirNames(t, dir)))
+	})
+
+	t.Run("emptyCase", func(t *testing.T) {
+		dir := t.TempDir()
+		manifest, err := OfElements().ToFiles(dir, byEventName, JSONCodec(newJSONEvent))
+		assert.NoError(t, err)
+		assert.Empty(t, manifest)
+		assert.Empty(t, dirNames(t, dir))
+	})
+
+	t.Run("failureCase", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "a-00000"), []byte("old\n"), 0o644))
+		manifest, err := events().Map(func(e types.T) types.R {
+			if e.(jsonEvent).ID == 3 {
+				return func() {}
+			}
+			return e
+		}).ToFiles(dir, func(e types.T) string {
+			if event, ok := e.(jsonEvent); ok {
+				return event.Name
+			}
+			return "a"
+		}, JSONCodec(nil), RotateByCount(1))
+		assert.Error(t, err)
+		assert.Nil(t, manifest)
+		assert.Equal(t, []string{"a-00000"}, dirNames(t, dir))
+		assert.Equal(t, "old\n", readFile(t, filepath.Join(dir, "a-00000")))
+	})
+
+	t.Run("samePathCase", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := OfElements("b", "a/../b").ToFiles(dir, func(e types.T) string {
+			return e.(string)
+		}, JSONCodec(nil))
+		assert.EqualError(t, err, `partitions "b" and "a/../b" write the same file `+filepath.Join(dir, "b-00000"))
+		assert.Empty(t, dirNames(t, dir))
+
+		_, err = events().ToFiles(dir, byEventName, JSONCodec(nil), RotateByCount(1),
+			WithFileName(func(partition string, sequence int) string {
+				return partition
+			}))
+		assert.EqualError(t, err, `partitions "a" and "a" write the same file `+filepath.Join(dir, "a"))
+		assert.Empty(t, dirNames(t, dir))
+	})
+
+	t.Run("renameFailureCase", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "a-00000"), []byte("old\n"), 0o644))
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, "b-00000", "keep"), 0o755))
+		manifest, err := events().ToFiles(dir, byEventName, JSONCodec(nil), RotateByCount(2))
+		assert.Error(t, err)
+		assert.Nil(t, manifest)
+		assert.Equal(t, []string{"a-00000"}, dirNames(t, dir))
+		assert.Equal(t, "old\n", readFile(t, filepath.Join(dir, "a-00000")))
+	})
+
+	t.Run("invalidPartitionCase", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := events().ToFiles(filepath.Join(dir, "out"), func(e types.T) string {
+			return "../escape"
+		}, JSONCodec(nil))
+		assert.EqualError(t, err, `invalid partition "../escape"`)
+		assert.Empty(t, dirNames(t, dir))
+	})
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	return string(data)
+}
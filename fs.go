@@ -0,0 +1,277 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"github.com/chinalhr/go-stream/types"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+//FSEntry Is an entry of a file system walked by OfFS, Path is the slash separated path of the entry in the
+//file system, Depth is the number of path elements below the root.
+type FSEntry struct {
+	Path  string
+	Depth int
+	Entry fs.DirEntry
+	Info  fs.FileInfo
+}
+
+//FileLine Is a line of a file streamed by FlatMapLines, Number is the 1-based line number.
+type FileLine struct {
+	Path   string
+	Number int
+	Text   string
+}
+
+//WalkOption Configures the walk of OfFS.
+type WalkOption func(o *walkOptions)
+
+//walkOptions
+//glob filters the entries by a path.Match pattern, entries are still walked if they do not match.
+//maxDepth is the max depth of the walked entries, -1 means unlimited.
+//skipDir skips the directories it returns true for, together with their contents.
+type walkOptions struct {
+	glob     string
+	maxDepth int
+	skipDir  func(e FSEntry) bool
+}
+
+//WithGlob Returns a WalkOption keeping the entries that match pattern by path.Match. A pattern without '/' is
+//matched against the base name of the entry, otherwise against its path.
+func WithGlob(pattern string) WalkOption {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic(err)
+	}
+	return func(o *walkOptions) {
+		o.glob = pattern
+	}
+}
+
+//WithMaxDepth Returns a WalkOption limiting the walk to the entries at most depth path elements below the root,
+//0 only yields the root.
+func WithMaxDepth(depth int) WalkOption {
+	return func(o *walkOptions) {
+		o.maxDepth = depth
+	}
+}
+
+//SkipDirs Returns a WalkOption skipping the directories below the root that predicate returns true for, a
+//skipped directory is neither yielded nor walked.
+func SkipDirs(predicate func(e FSEntry) bool) WalkOption {
+	return func(o *walkOptions) {
+		o.skipDir = predicate
+	}
+}
+
+//OfFS Return a sequential Stream of the FSEntry of the files and directories of fsys in and below root, in the
+//lexical order of fs.WalkDir. The walk runs lazily in a goroutine that is stopped once the Stream has been
+//evaluated, including when the evaluation is short-circuited. An error of walking fsys or reading the
+//fs.FileInfo of an entry ends the Stream and is returned by Err.
+func OfFS(fsys fs.FS, root string, opts ...WalkOption) Stream {
+	o := &walkOptions{maxDepth: -1}
+	for _, opt := range opts {
+		opt(o)
+	}
+	pipeline := newPipeline(&walkIterator{
+		fsys:     fsys,
+		root:     root,
+		options:  o,
+		entries:  make(chan FSEntry),
+		done:     make(chan struct{}),
+		finished: make(chan struct{}),
+	})
+	return Stream{pipeline}
+}
+
+//FlatMapLines Returns a Stream of the FileLine of the files of fsys, replacing every element with the lines of
+//the file it names. The elements are FSEntry or string paths, directories are skipped. The files are read lazily
+//and closed once their lines are streamed, an error of opening or reading a file stops the Stream and is
+//returned by Err. WithSplit and WithMaxTokenSize configure the lines like OfLines.
+func (s Stream) FlatMapLines(fsys fs.FS, opts ...IOOption) Stream {
+	pipeline := s.p
+	o := newIOOptions(opts)
+	pipeline.addOperation(func(next stage) stage {
+		return newDefaultIntermediateStage(next, beginFunc(func(size int) {
+			next.Begin(-1)
+		}), acceptFunc(func(e types.T) {
+			var name string
+			switch entry := e.(type) {
+			case FSEntry:
+				if entry.Entry.IsDir() {
+					return
+				}
+				name = entry.Path
+			case string:
+				name = entry
+			default:
+				pipeline.fail(fmt.Errorf("FlatMapLines: unsupported element type %T", e))
+				return
+			}
+			if err := streamLines(fsys, name, o, next); err != nil {
+				pipeline.fail(err)
+			}
+		}), cancellationRequestedFunc(func() bool {
+			return pipeline.failed() || next.CancellationRequested()
+		}))
+	})
+	return s
+}
+
+//streamLines Pass the lines of the file name of fsys to next until next requests cancellation.
+func streamLines(fsys fs.FS, name string, o *ioOptions, next stage) error {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	if info, err := file.Stat(); err == nil && info.IsDir() {
+		return file.Close()
+	}
+	lines := buildLinesIterator(file, o)
+	for number := 1; lines.HasNext() && !next.CancellationRequested(); number++ {
+		next.Accept(FileLine{Path: name, Number: number, Text: lines.Next().(string)})
+	}
+	if err := lines.Err(); err != nil {
+		_ = lines.Close()
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return lines.Close()
+}
+
+//errWalkStopped Stops fs.WalkDir once the walkIterator is closed.
+var errWalkStopped = errors.New("walk stopped")
+
+//walkIterator A general type iterator of the entries of fs.WalkDir. The walk is run by a goroutine started by
+//the first HasNext, which hands the entries over through a channel and is stopped by Close.
+type walkIterator struct {
+	iteratorInfiniteBaseInfo
+	fsys     fs.FS
+	root     string
+	options  *walkOptions
+	entries  chan FSEntry
+	done     chan struct{}
+	finished chan struct{}
+	next     FSEntry
+	hasNext  bool
+	started  bool
+	closed   bool
+	err      error
+	errMutex sync.Mutex
+}
+
+func (it *walkIterator) start() {
+	it.started = true
+	go func() {
+		defer close(it.finished)
+		defer close(it.entries)
+		err := fs.WalkDir(it.fsys, it.root, it.visit)
+		if err != nil && err != errWalkStopped {
+			it.errMutex.Lock()
+			it.err = err
+			it.errMutex.Unlock()
+		}
+	}()
+}
+
+//visit The fs.WalkDirFunc of the walk, yields the entries passing the options.
+func (it *walkIterator) visit(p string, d fs.DirEntry, err error) error {
+	if err != nil {
+		return err
+	}
+	depth := it.depth(p)
+	entry := FSEntry{Path: p, Depth: depth, Entry: d}
+	if d.IsDir() && depth > 0 && it.options.skipDir != nil && it.options.skipDir(entry) {
+		return fs.SkipDir
+	}
+	if it.options.maxDepth >= 0 && depth > it.options.maxDepth {
+		if d.IsDir() {
+			return fs.SkipDir
+		}
+		return nil
+	}
+	if it.matches(p) {
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entry.Info = info
+		select {
+		case it.entries <- entry:
+		case <-it.done:
+			return errWalkStopped
+		}
+	}
+	if d.IsDir() && depth == it.options.maxDepth {
+		return fs.SkipDir
+	}
+	return nil
+}
+
+//depth Returns the number of path elements of p below the root.
+func (it *walkIterator) depth(p string) int {
+	if p == it.root {
+		return 0
+	}
+	rel := p
+	if it.root != "." {
+		rel = strings.TrimPrefix(p, it.root+"/")
+	}
+	return strings.Count(rel, "/") + 1
+}
+
+func (it *walkIterator) matches(p string) bool {
+	if it.options.glob == "" {
+		return true
+	}
+	name := p
+	if !strings.Contains(it.options.glob, "/") {
+		name = path.Base(p)
+	}
+	matched, _ := path.Match(it.options.glob, name)
+	return matched
+}
+
+func (it *walkIterator) HasNext() bool {
+	if it.hasNext {
+		return true
+	}
+	if it.closed {
+		return false
+	}
+	if !it.started {
+		it.start()
+	}
+	e, ok := <-it.entries
+	if !ok {
+		return false
+	}
+	it.next, it.hasNext = e, true
+	return true
+}
+
+func (it *walkIterator) Next() types.T {
+	it.HasNext()
+	e := it.next
+	it.next, it.hasNext = FSEntry{}, false
+	return e
+}
+
+func (it *walkIterator) Err() error {
+	it.errMutex.Lock()
+	defer it.errMutex.Unlock()
+	return it.err
+}
+
+func (it *walkIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	close(it.done)
+	if it.started {
+		<-it.finished
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package stream
+
+import (
+	"errors"
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestFS() fstest.MapFS {
+	return fstest.MapFS{
+		"go.mod":              {Data: []byte("module example\n")},
+		"main.go":             {Data: []byte("package main\n\nfunc main() {}\n")},
+		"README.md":           {Data: []byte("# example\n")},
+		"internal/util.go":    {Data: []byte("package internal\n")},
+		"internal/deep/a.go":  {Data: []byte("package deep\n")},
+		"vendor/lib/lib.go":   {Data: []byte("package lib\n")},
+		"testdata/input.txt":  {Data: []byte("input\n")},
+		"internal/deep/b.txt": {Data: []byte("b\n")},
+	}
+}
+
+func entryPaths(s Stream) []types.T {
+	return s.Map(func(e types.T) types.R {
+		return e.(FSEntry).Path
+	}).ToSlice()
+}
+
+func TestOfFS(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream Stream
+		actual []types.T
+	}{
+		{
+			name:   "walkCase",
+			stream: OfFS(newTestFS(), "internal"),
+			actual: []types.T{"internal", "internal/deep", "internal/deep/a.go", "internal/deep/b.txt", "internal/util.go"},
+		},
+		{
+			name:   "globCase",
+			stream: OfFS(newTestFS(), ".", WithGlob("*.go")),
+			actual: []types.T{"internal/deep/a.go", "internal/util.go", "main.go", "vendor/lib/lib.go"},
+		},
+		{
+			name:   "pathGlobCase",
+			stream: OfFS(newTestFS(), ".", WithGlob("internal/*")),
+			actual: []types.T{"internal/deep", "internal/util.go"},
+		},
+		{
+			name:   "maxDepthCase",
+			stream: OfFS(newTestFS(), "internal", WithMaxDepth(1)),
+			actual: []types.T{"internal", "internal/deep", "internal/util.go"},
+		},
+		{
+			name: "skipDirsCase",
+			stream: OfFS(newTestFS(), ".", WithGlob("*.go"), SkipDirs(func(e FSEntry) bool {
+				return e.Path == "vendor" || e.Path == "internal/deep"
+			})),
+			actual: []types.T{"internal/util.go", "main.go"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.actual, entryPaths(test.stream))
+			assert.NoError(t, test.stream.Err())
+		})
+	}
+
+	t.Run("entryCase", func(t *testing.T) {
+		e := OfFS(newTestFS(), ".", WithGlob("util.go")).FindFirst().(FSEntry)
+		assert.Equal(t, 2, e.Depth)
+		assert.Equal(t, "util.go", e.Entry.Name())
+		assert.Equal(t, int64(len("package internal\n")), e.Info.Size())
+	})
+
+	t.Run("shortCircuitCase", func(t *testing.T) {
+		s := OfFS(newTestFS(), ".")
+		it := s.p.it.(*walkIterator)
+		assert.Equal(t, []types.T{"."}, entryPaths(s.Limit(1)))
+		assert.True(t, it.closed)
+		<-it.finished
+	})
+
+	t.Run("errCase", func(t *testing.T) {
+		s := OfFS(newTestFS(), "missing")
+		assert.Equal(t, 0, s.Count())
+		assert.True(t, errors.Is(s.Err(), fs.ErrNotExist))
+	})
+}
+
+func TestStream_FlatMapLines(t *testing.T) {
+	fsys := newTestFS()
+	actual := OfFS(fsys, ".", WithGlob("*.go")).Limit(2).FlatMapLines(fsys).ToSlice()
+	assert.Equal(t, []types.T{
+		FileLine{Path: "internal/deep/a.go", Number: 1, Text: "package deep"},
+		FileLine{Path: "internal/util.go", Number: 1, Text: "package internal"},
+	}, actual)
+
+	t.Run("pathCase", func(t *testing.T) {
+		lines := OfElements("main.go").FlatMapLines(fsys).Limit(2).ToSlice()
+		assert.Equal(t, []types.T{
+			FileLine{Path: "main.go", Number: 1, Text: "package main"},
+			FileLine{Path: "main.go", Number: 2, Text: ""},
+		}, lines)
+	})
+
+	t.Run("errCase", func(t *testing.T) {
+		s := OfElements("main.go", "missing.go", "go.mod").FlatMapLines(fsys)
+		assert.Equal(t, 3, s.Count())
+		assert.True(t, errors.Is(s.Err(), fs.ErrNotExist))
+	})
+}
@@ -0,0 +1,279 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/chinalhr/go-stream/types"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+//typeRegistry The types that untyped elements are encoded as, by name.
+var typeRegistry = struct {
+	sync.RWMutex
+	types map[string]reflect.Type
+	names map[reflect.Type]string
+}{
+	types: make(map[string]reflect.Type),
+	names: make(map[reflect.Type]string),
+}
+
+func init() {
+	for _, value := range []types.T{
+		false, "", []byte(nil), time.Time{},
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0), complex64(0), complex128(0),
+		[]int(nil), []string(nil), []float64(nil), []types.T(nil), map[string]types.T(nil),
+		types.KV{},
+	} {
+		RegisterType(value)
+	}
+}
+
+//RegisterType Register the concrete type of value, so that elements of the type round-trip through ToGob and
+//OfGob, or through ToFrames and OfFrames with EncodeTyped and DecodeTyped or with TypedFrameEncoder and
+//TypedFrameDecoder, as untyped types.T elements.
+//The builtin scalar types, time.Time and types.KV are registered in advance.
+func RegisterType(value types.T) {
+	gob.Register(value)
+	t := reflect.TypeOf(value)
+	name := t.String()
+	if t.Name() != "" && t.PkgPath() != "" {
+		name = t.PkgPath() + "." + t.Name()
+	}
+	typeRegistry.Lock()
+	defer typeRegistry.Unlock()
+	typeRegistry.types[name] = t
+	typeRegistry.names[t] = name
+}
+
+//EncodeTyped Encode e by encoding/gob as a frame payload that names its type, the type of e needs to be
+//registered by RegisterType. It is the encode function of ToFrames for untyped elements. Every payload can be
+//decoded on its own, so it repeats the type name and the gob type descriptor of e, which usually outweigh the
+//value itself, TypedFrameEncoder writes them only once per stream.
+func EncodeTyped(e types.T) ([]byte, error) {
+	name, err := typeName(e)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writeUvarint(&buf, uint64(len(name)))
+	buf.WriteString(name)
+	if err := gob.NewEncoder(&buf).Encode(e); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+//DecodeTyped Decode a frame payload encoded by EncodeTyped into a value of the registered type it names.
+//It is the decode function of OfFrames for untyped elements.
+func DecodeTyped(payload []byte) (types.T, error) {
+	t, value, err := readTypeName(payload)
+	if err != nil {
+		return nil, err
+	}
+	ptr := reflect.New(t)
+	if err := gob.NewDecoder(bytes.NewReader(value)).DecodeValue(ptr); err != nil {
+		return nil, err
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+//TypedFrameEncoder Returns an encode function of ToFrames for untyped elements, that is more compact than
+//EncodeTyped: the name and the gob type descriptor of a type are only written into the first payload of the type,
+//the following payloads hold the index of the type and the value. The payloads need to be decoded in order by a
+//function returned by TypedFrameDecoder, a new encode function is needed for every stream.
+func TypedFrameEncoder() func(e types.T) ([]byte, error) {
+	var mutex sync.Mutex
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	ids := make(map[reflect.Type]uint64)
+	return func(e types.T) ([]byte, error) {
+		name, err := typeName(e)
+		if err != nil {
+			return nil, err
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		buf.Reset()
+		t := reflect.TypeOf(e)
+		id, ok := ids[t]
+		if !ok {
+			id = uint64(len(ids))
+		}
+		writeUvarint(&buf, id)
+		if !ok {
+			writeUvarint(&buf, uint64(len(name)))
+			buf.WriteString(name)
+		}
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+		ids[t] = id
+		return append([]byte(nil), buf.Bytes()...), nil
+	}
+}
+
+//TypedFrameDecoder Returns a decode function of OfFrames for the payloads written by a function returned by
+//TypedFrameEncoder, the payloads need to be decoded in the order they were written, a new decode function is
+//needed for every stream.
+func TypedFrameDecoder() func(payload []byte) (types.T, error) {
+	var mutex sync.Mutex
+	var buf bytes.Buffer
+	dec := gob.NewDecoder(&buf)
+	var known []reflect.Type
+	return func(payload []byte) (types.T, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		id, n := binary.Uvarint(payload)
+		if n <= 0 || id > uint64(len(known)) {
+			return nil, errors.New("malformed typed payload")
+		}
+		payload = payload[n:]
+		var t reflect.Type
+		if id < uint64(len(known)) {
+			t = known[id]
+		} else {
+			var err error
+			if t, payload, err = readTypeName(payload); err != nil {
+				return nil, err
+			}
+		}
+		buf.Reset()
+		buf.Write(payload)
+		ptr := reflect.New(t)
+		if err := dec.DecodeValue(ptr); err != nil {
+			return nil, err
+		}
+		if id == uint64(len(known)) {
+			known = append(known, t)
+		}
+		return ptr.Elem().Interface(), nil
+	}
+}
+
+//typeName Returns the registered name of the type of e.
+func typeName(e types.T) (string, error) {
+	if e == nil {
+		return "", errors.New("can not encode a nil element")
+	}
+	t := reflect.TypeOf(e)
+	typeRegistry.RLock()
+	name, ok := typeRegistry.names[t]
+	typeRegistry.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("type %v is not registered", t)
+	}
+	return name, nil
+}
+
+//writeUvarint Write x as a uvarint.
+func writeUvarint(buf *bytes.Buffer, x uint64) {
+	var header [binary.MaxVarintLen64]byte
+	buf.Write(header[:binary.PutUvarint(header[:], x)])
+}
+
+//readTypeName Returns the registered type named by the uvarint length and the name at the start of payload, and
+//the rest of payload.
+func readTypeName(payload []byte) (reflect.Type, []byte, error) {
+	length, n := binary.Uvarint(payload)
+	if n <= 0 || uint64(len(payload)-n) < length {
+		return nil, nil, errors.New("malformed typed payload")
+	}
+	name := string(payload[n : n+int(length)])
+	typeRegistry.RLock()
+	t, ok := typeRegistry.types[name]
+	typeRegistry.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("type %s is not registered", name)
+	}
+	return t, payload[n+int(length):], nil
+}
+
+//OfGob Return a sequential Stream of the values decoded by encoding/gob from r.
+//If newElem is nil, r holds interface values, such as the elements written by ToGob, and their concrete types
+//need to be registered by RegisterType. Otherwise r holds values of a single type, newElem returns a pointer to a
+//new value that a value is decoded into and the element is the value the pointer points to. r is read lazily, a
+//decoding error ends the Stream and is returned by Err. If r is an io.Closer, it is closed once the Stream has
+//been evaluated.
+func OfGob(r io.Reader, newElem func() types.T, opts ...IOOption) Stream {
+	r = openReader(r, newIOOptions(opts))
+	it := buildDecoderIterator(&gobDecoder{dec: gob.NewDecoder(r), newElem: newElem}, -1)
+	it.reader = r
+	pipeline := newPipeline(it)
+	return Stream{pipeline}
+}
+
+//ToGob Write the elements to w by encoding/gob as interface values, which keep the concrete types of the
+//elements when they are read back by OfGob with a nil newElem. The types need to be registered by RegisterType.
+//Returns the first error of encoding, writing or evaluating the Stream.
+func (s Stream) ToGob(w io.Writer, opts ...IOOption) error {
+	out, flush := openWriter(w, newIOOptions(opts))
+	enc := GobCodec().NewEncoder(out)
+	return closeWriter(writeEach(s.p, enc.Encode), flush)
+}
+
+//OfFrames Return a sequential Stream of the elements decoded by decode from the length-prefixed frames of r.
+//A frame is the 4-byte big-endian length of its payload followed by the payload, as written by ToFrames.
+//r is read lazily, a truncated frame or a decoding error ends the Stream and is returned by Err. If r is an
+//io.Closer, it is closed once the Stream has been evaluated.
+func OfFrames(r io.Reader, decode func(payload []byte) (types.T, error), opts ...IOOption) Stream {
+	r = openReader(r, newIOOptions(opts))
+	it := buildDecoderIterator(&frameDecoder{r: bufio.NewReader(r), decode: decode}, -1)
+	it.reader = r
+	pipeline := newPipeline(it)
+	return Stream{pipeline}
+}
+
+//ToFrames Write the elements to w as length-prefixed frames of the payloads returned by encode.
+//Returns the first error of encoding, writing or evaluating the Stream.
+func (s Stream) ToFrames(w io.Writer, encode func(e types.T) ([]byte, error), opts ...IOOption) error {
+	out, flush := openWriter(w, newIOOptions(opts))
+	var header [4]byte
+	err := writeEach(s.p, func(e types.T) error {
+		payload, err := encode(e)
+		if err != nil {
+			return err
+		}
+		if uint64(len(payload)) > 1<<32-1 {
+			return errors.New("frame payload is too large")
+		}
+		binary.BigEndian.PutUint32(header[:], uint32(len(payload)))
+		if _, err := out.Write(header[:]); err != nil {
+			return err
+		}
+		_, err = out.Write(payload)
+		return err
+	})
+	return closeWriter(err, flush)
+}
+
+//frameDecoder Reads length-prefixed frames, the payload buffer grows with the bytes actually read, so a corrupt
+//length does not allocate a huge buffer.
+type frameDecoder struct {
+	r      *bufio.Reader
+	decode func(payload []byte) (types.T, error)
+}
+
+func (d *frameDecoder) Decode() (types.T, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(d.r, header[:]); err != nil {
+		return nil, err
+	}
+	length := int64(binary.BigEndian.Uint32(header[:]))
+	var buf bytes.Buffer
+	if n, err := io.CopyN(&buf, d.r, length); err != nil {
+		if err == io.EOF && n < length {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return d.decode(buf.Bytes())
+}
@@ -0,0 +1,132 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/gob"
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"time"
+)
+
+type gobPoint struct {
+	X, Y int
+}
+
+type gobUnregistered struct {
+	Name string
+}
+
+func init() {
+	RegisterType(gobPoint{})
+}
+
+func untypedElements() []types.T {
+	return []types.T{
+		1, int64(2), "three", 4.5, true,
+		gobPoint{X: 1, Y: 2},
+		time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC),
+		types.KV{KEY: "k", VALUE: gobPoint{X: 3}},
+	}
+}
+
+func TestGob(t *testing.T) {
+	t.Run("roundTripCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, OfElements(untypedElements()...).ToGob(&buf))
+		s := OfGob(&buf, nil)
+		assert.Equal(t, untypedElements(), s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+
+	t.Run("typedCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := gob.NewEncoder(&buf)
+		assert.NoError(t, enc.Encode(gobPoint{X: 1}))
+		assert.NoError(t, enc.Encode(gobPoint{Y: 2}))
+		r := newTrackingReader(buf.String())
+		s := OfGob(r, func() types.T {
+			return &gobPoint{}
+		})
+		assert.Equal(t, []types.T{gobPoint{X: 1}, gobPoint{Y: 2}}, s.ToSlice())
+		assert.True(t, r.closed)
+	})
+
+	t.Run("unregisteredCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := OfElements(1, gobUnregistered{Name: "x"}).ToGob(&buf)
+		assert.Error(t, err)
+	})
+
+	t.Run("truncatedCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, OfElements(untypedElements()...).ToGob(&buf))
+		s := OfGob(bytes.NewReader(buf.Bytes()[:buf.Len()-3]), nil)
+		assert.Equal(t, len(untypedElements())-1, s.Count())
+		assert.Error(t, s.Err())
+	})
+}
+
+func TestFrames(t *testing.T) {
+	t.Run("typedRoundTripCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, OfElements(untypedElements()...).ToFrames(&buf, EncodeTyped))
+		s := OfFrames(&buf, DecodeTyped)
+		assert.Equal(t, untypedElements(), s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+
+	t.Run("compactRoundTripCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		elements := append(untypedElements(), untypedElements()...)
+		assert.NoError(t, OfElements(elements...).ToFrames(&buf, TypedFrameEncoder()))
+		s := OfFrames(&buf, TypedFrameDecoder())
+		assert.Equal(t, elements, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+
+	t.Run("compactSizeCase", func(t *testing.T) {
+		encode := TypedFrameEncoder()
+		first, err := encode(gobPoint{X: 1, Y: 2})
+		assert.NoError(t, err)
+		second, err := encode(gobPoint{X: 3, Y: 4})
+		assert.NoError(t, err)
+		typed, err := EncodeTyped(gobPoint{X: 3, Y: 4})
+		assert.NoError(t, err)
+		assert.Equal(t, len(typed)+1, len(first))
+		assert.Less(t, len(second)*5, len(typed))
+
+		decode := TypedFrameDecoder()
+		_, err = decode(second)
+		assert.Error(t, err)
+	})
+
+	t.Run("customCodecCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := OfElements("a", "", "bc").ToFrames(&buf, func(e types.T) ([]byte, error) {
+			return []byte(e.(string)), nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0, 0, 0, 1, 'a', 0, 0, 0, 0, 0, 0, 0, 2, 'b', 'c'}, buf.Bytes())
+
+		actual := OfFrames(&buf, func(payload []byte) (types.T, error) {
+			return string(payload), nil
+		}).ToSlice()
+		assert.Equal(t, []types.T{"a", "", "bc"}, actual)
+	})
+
+	t.Run("truncatedCase", func(t *testing.T) {
+		s := OfFrames(bytes.NewReader([]byte{0, 0, 0, 1, 'a', 0, 0, 0, 9, 'b'}), func(payload []byte) (types.T, error) {
+			return string(payload), nil
+		})
+		assert.Equal(t, []types.T{"a"}, s.ToSlice())
+		assert.Equal(t, io.ErrUnexpectedEOF, s.Err())
+	})
+
+	t.Run("unregisteredCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := OfElements(gobUnregistered{Name: "x"}).ToFrames(&buf, EncodeTyped)
+		assert.EqualError(t, err, "type stream.gobUnregistered is not registered")
+	})
+}
@@ -0,0 +1,164 @@
+package stream
+
+import (
+	"github.com/chinalhr/go-stream/types"
+	"sync"
+)
+
+//GroupBy Returns a Stream of types.Group, grouping the elements by the key calculated by the classifier function.
+//Unlike GroupingBy, the groups can be processed by further operations. The groups are emitted in the order in
+//which their keys first appear, the elements of a group keep their encounter order. Keys are compared by ==.
+func (s Stream) GroupBy(classifier func(t types.T) types.K) Stream {
+	pipeline := s.p
+	pipeline.addOperation(func(next stage) stage {
+		var groups *orderedGroups
+		var mutex sync.Mutex
+		return newDefaultIntermediateStage(next, beginFunc(func(size int) {
+			groups = newOrderedGroups(false)
+			next.Begin(-1)
+		}), acceptFunc(func(e types.T) {
+			key := classifier(e)
+			mutex.Lock()
+			defer mutex.Unlock()
+			groups.add(key, e)
+		}), endFunc(func() {
+			for i := 0; i < len(groups.keys) && !next.CancellationRequested(); i++ {
+				key := groups.keys[i]
+				next.Accept(types.Group{Key: key, Elements: groups.elements[key]})
+			}
+			groups = nil
+			next.End()
+		}))
+	})
+	return s
+}
+
+//CoGroup Return a Stream of types.CoGroup, grouping the elements of a by the key calculated by keyA and the
+//elements of b by the key calculated by keyB. A key that only appears in one stream has an empty group for the
+//other stream. The groups are emitted in the order in which their keys first appear in a, followed by the keys
+//that only appear in b. Both streams are evaluated when the Stream is first pulled.
+func CoGroup(a Stream, b Stream, keyA func(e types.T) types.K, keyB func(e types.T) types.K) Stream {
+	pipeline := newPipeline(&coGroupIterator{
+		a:    a,
+		b:    b,
+		keyA: keyA,
+		keyB: keyB,
+	})
+	return Stream{pipeline}
+}
+
+//orderedGroups Groups elements by key, keys keep the order in which they first appear and the elements of a key
+//keep their encounter order. It backs GroupBy, CoGroup, the joins, the set operations and the window functions.
+//If order is not nil, it holds the key of every element in encounter order, which each uses to visit the
+//elements in encounter order.
+type orderedGroups struct {
+	keys     []types.K
+	elements map[types.K][]types.T
+	order    []types.K
+}
+
+//newOrderedGroups Returns an empty orderedGroups, keepOrder keeps the encounter order of all elements for each.
+func newOrderedGroups(keepOrder bool) *orderedGroups {
+	g := &orderedGroups{
+		keys:     make([]types.K, 0),
+		elements: make(map[types.K][]types.T),
+	}
+	if keepOrder {
+		g.order = make([]types.K, 0)
+	}
+	return g
+}
+
+func (g *orderedGroups) add(key types.K, e types.T) {
+	if _, ok := g.elements[key]; !ok {
+		g.keys = append(g.keys, key)
+	}
+	g.elements[key] = append(g.elements[key], e)
+	if g.order != nil {
+		g.order = append(g.order, key)
+	}
+}
+
+//each Call fn with the elements in encounter order until it returns false, index is the position of e in the
+//group of key. The groups need to be created with keepOrder.
+func (g *orderedGroups) each(fn func(key types.K, index int, e types.T) bool) {
+	seen := make(map[types.K]int)
+	for _, key := range g.order {
+		index := seen[key]
+		seen[key]++
+		if !fn(key, index, g.elements[key][index]) {
+			return
+		}
+	}
+}
+
+//groupStream Evaluate s and group its elements by the key calculated by keyFn, keepOrder is passed to
+//newOrderedGroups.
+func groupStream(s Stream, keyFn func(e types.T) types.K, keepOrder bool) *orderedGroups {
+	groups := newOrderedGroups(keepOrder)
+	var mutex sync.Mutex
+	s.ForEach(func(e types.T) {
+		key := keyFn(e)
+		mutex.Lock()
+		defer mutex.Unlock()
+		groups.add(key, e)
+	})
+	return groups
+}
+
+//coGroupIterator A general type iterator of the types.CoGroup of two streams, the streams are grouped by the
+//first HasNext.
+type coGroupIterator struct {
+	a, b        Stream
+	keyA, keyB  func(e types.T) types.K
+	groupsA     *orderedGroups
+	groupsB     *orderedGroups
+	keys        []types.K
+	index       int
+	initialized bool
+}
+
+func (it *coGroupIterator) init() {
+	if it.initialized {
+		return
+	}
+	it.initialized = true
+	it.groupsA = groupStream(it.a, it.keyA, false)
+	it.groupsB = groupStream(it.b, it.keyB, false)
+	it.keys = append(make([]types.K, 0, len(it.groupsA.keys)), it.groupsA.keys...)
+	for _, key := range it.groupsB.keys {
+		if _, ok := it.groupsA.elements[key]; !ok {
+			it.keys = append(it.keys, key)
+		}
+	}
+}
+
+func (it *coGroupIterator) GetSize() int {
+	return -1
+}
+
+func (it *coGroupIterator) HasNext() bool {
+	it.init()
+	return it.index < len(it.keys)
+}
+
+func (it *coGroupIterator) Next() types.T {
+	it.init()
+	key := it.keys[it.index]
+	it.index++
+	left, right := it.groupsA.elements[key], it.groupsB.elements[key]
+	if left == nil {
+		left = []types.T{}
+	}
+	if right == nil {
+		right = []types.T{}
+	}
+	return types.CoGroup{Key: key, Left: left, Right: right}
+}
+
+func (it *coGroupIterator) Err() error {
+	if err := it.a.Err(); err != nil {
+		return err
+	}
+	return it.b.Err()
+}
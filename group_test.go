@@ -0,0 +1,94 @@
+package stream
+
+import (
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStream_GroupBy(t *testing.T) {
+	parity := func(e types.T) types.K {
+		return e.(int) % 3
+	}
+
+	tests := []struct {
+		name   string
+		stream Stream
+		actual []types.T
+	}{
+		{
+			name:   "groupByCase",
+			stream: OfElements(4, 1, 3, 7, 6, 2),
+			actual: []types.T{
+				types.Group{Key: 1, Elements: []types.T{4, 1, 7}},
+				types.Group{Key: 0, Elements: []types.T{3, 6}},
+				types.Group{Key: 2, Elements: []types.T{2}},
+			},
+		},
+		{
+			name:   "emptyCase",
+			stream: OfElements(),
+			actual: []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.actual, test.stream.GroupBy(parity).ToSlice())
+		})
+	}
+
+	t.Run("groupTransformCase", func(t *testing.T) {
+		sizes := RangeClosed(1, 10, 1).GroupBy(parity).
+			Filter(func(e types.T) bool {
+				return len(e.(types.Group).Elements) > 3
+			}).
+			Map(func(e types.T) types.R {
+				return e.(types.Group).Key
+			}).ToSlice()
+		assert.Equal(t, []types.T{1}, sizes)
+	})
+
+	t.Run("parallelCase", func(t *testing.T) {
+		counts := RangeClosed(1, 1000, 1).Parallel(4).GroupBy(parity).
+			Map(func(e types.T) types.R {
+				return len(e.(types.Group).Elements)
+			}).
+			Reduce(func(e1 types.T, e2 types.T) types.T {
+				return e1.(int) + e2.(int)
+			})
+		assert.Equal(t, 1000, counts)
+	})
+}
+
+func TestCoGroup(t *testing.T) {
+	type order struct {
+		customer string
+		amount   int
+	}
+	type payment struct {
+		customer string
+		paid     int
+	}
+	orders := []types.T{order{"a", 1}, order{"b", 2}, order{"a", 3}}
+	payments := []types.T{payment{"c", 5}, payment{"a", 4}}
+	orderKey := func(e types.T) types.K {
+		return e.(order).customer
+	}
+	paymentKey := func(e types.T) types.K {
+		return e.(payment).customer
+	}
+
+	actual := CoGroup(OfElements(orders...), OfElements(payments...), orderKey, paymentKey).ToSlice()
+	assert.Equal(t, []types.T{
+		types.CoGroup{Key: "a", Left: []types.T{orders[0], orders[2]}, Right: []types.T{payments[1]}},
+		types.CoGroup{Key: "b", Left: []types.T{orders[1]}, Right: []types.T{}},
+		types.CoGroup{Key: "c", Left: []types.T{}, Right: []types.T{payments[0]}},
+	}, actual)
+
+	t.Run("errCase", func(t *testing.T) {
+		s := CoGroup(OfElements(orders...), Stream{newPipeline(&failingIterator{})}, orderKey, paymentKey)
+		assert.Equal(t, 2, s.Count())
+		assert.EqualError(t, s.Err(), "failed")
+	})
+}
@@ -0,0 +1,96 @@
+package stream
+
+import (
+	"container/heap"
+	"github.com/chinalhr/go-stream/types"
+	"sort"
+)
+
+//heapElement An element retained by boundedHeap, seq is the encounter order of the element.
+type heapElement struct {
+	value types.T
+	seq   int
+}
+
+//boundedHeap Retains the k smallest elements according to the compare function.
+//The root of the heap is the largest retained element, so a smaller element can replace it in O(log k).
+//Elements that compare equal are ordered by their encounter order, which keeps the result stable.
+type boundedHeap struct {
+	elements []heapElement
+	limit    int
+	seq      int
+	compare  func(e1 types.T, e2 types.T) int
+}
+
+func newBoundedHeap(limit int, compare func(e1 types.T, e2 types.T) int) *boundedHeap {
+	if limit < 0 {
+		limit = 0
+	}
+	return &boundedHeap{
+		elements: make([]heapElement, 0),
+		limit:    limit,
+		compare:  compare,
+	}
+}
+
+//order Compare two retained elements, falling back to the encounter order when compare returns 0.
+func (h *boundedHeap) order(e1 heapElement, e2 heapElement) int {
+	if c := h.compare(e1.value, e2.value); c != 0 {
+		return c
+	}
+	return e1.seq - e2.seq
+}
+
+func (h *boundedHeap) Len() int {
+	return len(h.elements)
+}
+
+func (h *boundedHeap) Less(i, j int) bool {
+	return h.order(h.elements[i], h.elements[j]) > 0
+}
+
+func (h *boundedHeap) Swap(i, j int) {
+	h.elements[i], h.elements[j] = h.elements[j], h.elements[i]
+}
+
+func (h *boundedHeap) Push(x interface{}) {
+	h.elements = append(h.elements, x.(heapElement))
+}
+
+func (h *boundedHeap) Pop() interface{} {
+	last := len(h.elements) - 1
+	e := h.elements[last]
+	h.elements = h.elements[:last]
+	return e
+}
+
+//offer Add e to the heap, the largest element is evicted once the heap holds more than limit elements.
+func (h *boundedHeap) offer(e types.T) {
+	element := heapElement{value: e, seq: h.seq}
+	h.seq++
+	if h.limit == 0 {
+		return
+	}
+	if len(h.elements) < h.limit {
+		heap.Push(h, element)
+		return
+	}
+	if h.order(element, h.elements[0]) < 0 {
+		h.elements[0] = element
+		heap.Fix(h, 0)
+	}
+}
+
+//sorted Returns the retained elements in ascending order.
+func (h *boundedHeap) sorted() []types.T {
+	elements := make([]heapElement, len(h.elements))
+	copy(elements, h.elements)
+	sort.Slice(elements, func(i, j int) bool {
+		return h.order(elements[i], elements[j]) < 0
+	})
+	result := make([]types.T, 0, len(elements))
+	for _, e := range elements {
+		result = append(result, e.value)
+	}
+	return result
+}
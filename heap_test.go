@@ -0,0 +1,60 @@
+package stream
+
+import (
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBoundedHeap(t *testing.T) {
+	type pair struct {
+		key   int
+		value string
+	}
+	compare := func(e1 types.T, e2 types.T) int {
+		return e1.(pair).key - e2.(pair).key
+	}
+
+	tests := []struct {
+		name   string
+		limit  int
+		input  []types.T
+		actual []types.T
+	}{
+		{
+			name:   "normalCase",
+			limit:  3,
+			input:  []types.T{pair{5, "a"}, pair{1, "b"}, pair{4, "c"}, pair{2, "d"}, pair{3, "e"}},
+			actual: []types.T{pair{1, "b"}, pair{2, "d"}, pair{3, "e"}},
+		},
+		{
+			name:   "stableCase",
+			limit:  2,
+			input:  []types.T{pair{2, "a"}, pair{1, "b"}, pair{2, "c"}, pair{1, "d"}, pair{1, "e"}},
+			actual: []types.T{pair{1, "b"}, pair{1, "d"}},
+		},
+		{
+			name:   "lessThanLimitCase",
+			limit:  10,
+			input:  []types.T{pair{2, "a"}, pair{1, "b"}},
+			actual: []types.T{pair{1, "b"}, pair{2, "a"}},
+		},
+		{
+			name:   "zeroLimitCase",
+			limit:  0,
+			input:  []types.T{pair{2, "a"}, pair{1, "b"}},
+			actual: []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newBoundedHeap(test.limit, compare)
+			for _, e := range test.input {
+				h.offer(e)
+			}
+			assert.LessOrEqual(t, h.Len(), test.limit)
+			assert.Equal(t, test.actual, h.sorted())
+		})
+	}
+}
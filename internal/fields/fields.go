@@ -0,0 +1,58 @@
+//Package fields Resolves the dot separated field paths used by the comparator and predicate packages, so that a
+//path names the same field everywhere.
+package fields
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//Split Returns the names of the dot separated path, such as "Size.Weight".
+func Split(path string) []string {
+	return strings.Split(path, ".")
+}
+
+//ByPath Returns the field of v at path, v and the fields along the path can be structs or pointers or interfaces
+//holding structs. Names are matched case-insensitively if no field matches exactly, unexported fields are
+//supported. Returns an error if a nil pointer, a non-struct value or a missing field is met along the path.
+func ByPath(v reflect.Value, path []string) (reflect.Value, error) {
+	for _, name := range path {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, fmt.Errorf("field %s of nil value", name)
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			if !v.IsValid() {
+				return reflect.Value{}, fmt.Errorf("field %s of nil value", name)
+			}
+			return reflect.Value{}, fmt.Errorf("field %s of non-struct type %s", name, v.Type())
+		}
+		field := v.FieldByName(name)
+		if !field.IsValid() {
+			field = v.FieldByNameFunc(func(fieldName string) bool {
+				return strings.EqualFold(fieldName, name)
+			})
+		}
+		if !field.IsValid() {
+			return reflect.Value{}, fmt.Errorf("type %s has no field %s", v.Type(), name)
+		}
+		v = field
+	}
+	return v, nil
+}
+
+//IsNil Returns true if v is invalid, such as the reflect.Value of a nil interface, or a nil pointer, map, slice,
+//channel, function or interface.
+func IsNil(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
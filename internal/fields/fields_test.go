@@ -0,0 +1,59 @@
+package fields
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+type size struct {
+	weight int
+}
+
+type widget struct {
+	Color string
+	Size  *size
+}
+
+func TestByPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		path   string
+		actual interface{}
+		err    string
+	}{
+		{name: "exactCase", value: widget{Color: "red"}, path: "Color", actual: "red"},
+		{name: "caseInsensitiveCase", value: &widget{Color: "red"}, path: "color", actual: "red"},
+		{name: "nestedCase", value: widget{Size: &size{2}}, path: "size.weight", actual: int64(2)},
+		{name: "nilCase", value: widget{}, path: "Size.weight", err: "field weight of nil value"},
+		{name: "missingCase", value: widget{}, path: "Price", err: "type fields.widget has no field Price"},
+		{name: "nonStructCase", value: 1, path: "Color", err: "field Color of non-struct type int"},
+		{name: "nilElementCase", value: nil, path: "Color", err: "field Color of nil value"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			field, err := ByPath(reflect.ValueOf(test.value), Split(test.path))
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			switch field.Kind() {
+			case reflect.Int:
+				assert.Equal(t, test.actual, field.Int())
+			default:
+				assert.Equal(t, test.actual, field.Interface())
+			}
+		})
+	}
+}
+
+func TestIsNil(t *testing.T) {
+	var w *widget
+	assert.True(t, IsNil(reflect.ValueOf(nil)))
+	assert.True(t, IsNil(reflect.ValueOf(w)))
+	assert.False(t, IsNil(reflect.ValueOf(widget{})))
+	assert.False(t, IsNil(reflect.ValueOf(0)))
+}
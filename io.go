@@ -0,0 +1,269 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"github.com/chinalhr/go-stream/types"
+	"io"
+	"sync"
+	"time"
+)
+
+//IOOption Configures the sources reading from an io.Reader and the terminal operations writing to an io.Writer.
+type IOOption func(o *ioOptions)
+
+//ioOptions
+//split is the split function of the scanner, bufio.ScanLines by default.
+//maxTokenSize is the max size of a token read by the scanner, bufio.MaxScanTokenSize by default.
+//skipBad skips the records that can not be decoded instead of failing the Stream.
+//comma, header, timeLayout and newElem configure CSV, see OfCSV, newElem also configures OfFile.
+//gzip decompresses the input and compresses the output with gzip.
+type ioOptions struct {
+	split        bufio.SplitFunc
+	maxTokenSize int
+	skipBad      bool
+	comma        rune
+	header       []string
+	timeLayout   string
+	newElem      func() types.T
+	gzip         bool
+}
+
+//WithSplit Returns an IOOption splitting the input into tokens by split, such as bufio.ScanWords.
+func WithSplit(split bufio.SplitFunc) IOOption {
+	return func(o *ioOptions) {
+		o.split = split
+	}
+}
+
+//WithMaxTokenSize Returns an IOOption limiting the size of a token to n bytes, a longer token fails the Stream
+//with bufio.ErrTooLong.
+func WithMaxTokenSize(n int) IOOption {
+	return func(o *ioOptions) {
+		o.maxTokenSize = n
+	}
+}
+
+//SkipBadRecords Returns an IOOption skipping the records that can not be decoded, such as malformed JSON lines,
+//instead of failing the Stream.
+func SkipBadRecords() IOOption {
+	return func(o *ioOptions) {
+		o.skipBad = true
+	}
+}
+
+//WithGzip Returns an IOOption decompressing the input of a source, or compressing the output of a terminal
+//operation, with gzip.
+func WithGzip() IOOption {
+	return func(o *ioOptions) {
+		o.gzip = true
+	}
+}
+
+func newIOOptions(opts []IOOption) *ioOptions {
+	o := &ioOptions{
+		split:        bufio.ScanLines,
+		maxTokenSize: bufio.MaxScanTokenSize,
+		comma:        ',',
+		timeLayout:   time.RFC3339,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+//OfLines Return a sequential Stream of the lines of r as strings, without the line endings.
+//r is read lazily while the Stream is evaluated and the size of the Stream is unknown. A read error ends the
+//Stream and is returned by Err. If r is an io.Closer, it is closed once the Stream has been evaluated, including
+//when the evaluation is short-circuited.
+func OfLines(r io.Reader, opts ...IOOption) Stream {
+	pipeline := newPipeline(buildLinesIterator(r, newIOOptions(opts)))
+	return Stream{pipeline}
+}
+
+//ToLines Write the elements to w one per line. An element is written as itself if it is a string or []byte, by
+//String if it is a fmt.Stringer, and by fmt.Sprint otherwise.
+//Returns the first error of writing or evaluating the Stream.
+func (s Stream) ToLines(w io.Writer, opts ...IOOption) error {
+	out, flush := openWriter(w, newIOOptions(opts))
+	buffered := bufio.NewWriter(out)
+	err := writeEach(s.p, func(e types.T) error {
+		_, err := buffered.WriteString(lineOf(e) + "\n")
+		return err
+	})
+	return closeWriter(err, func() error {
+		return closeWriter(buffered.Flush(), flush)
+	})
+}
+
+//OfScanner Return a sequential Stream of the tokens of scanner as strings.
+//The scanner is used as configured by the caller, whose reader is not closed by the Stream.
+func OfScanner(scanner *bufio.Scanner) Stream {
+	pipeline := newPipeline(buildScannerIterator(scanner, nil))
+	return Stream{pipeline}
+}
+
+//scannerIterator A general type iterator of the tokens of a bufio.Scanner, reader is closed by Close if it is
+//an io.Closer.
+type scannerIterator struct {
+	iteratorInfiniteBaseInfo
+	scanner *bufio.Scanner
+	reader  io.Reader
+	next    string
+	hasNext bool
+	done    bool
+	closed  bool
+}
+
+func (it *scannerIterator) HasNext() bool {
+	if it.hasNext {
+		return true
+	}
+	if it.done {
+		return false
+	}
+	if !it.scanner.Scan() {
+		it.done = true
+		return false
+	}
+	it.next, it.hasNext = it.scanner.Text(), true
+	return true
+}
+
+func (it *scannerIterator) Next() types.T {
+	it.HasNext()
+	e := it.next
+	it.next, it.hasNext = "", false
+	return e
+}
+
+func (it *scannerIterator) Err() error {
+	return it.scanner.Err()
+}
+
+func (it *scannerIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	it.done = true
+	if closer, ok := it.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func buildScannerIterator(scanner *bufio.Scanner, reader io.Reader) *scannerIterator {
+	return &scannerIterator{
+		scanner: scanner,
+		reader:  reader,
+	}
+}
+
+//buildLinesIterator Returns a scannerIterator of r split and decompressed as configured by o.
+func buildLinesIterator(r io.Reader, o *ioOptions) *scannerIterator {
+	r = openReader(r, o)
+	scanner := bufio.NewScanner(r)
+	scanner.Split(o.split)
+	scanner.Buffer(make([]byte, 0, minInt(o.maxTokenSize, 4096)), o.maxTokenSize)
+	return buildScannerIterator(scanner, r)
+}
+
+//writeEach Evaluate the pipeline writing every element by write, the writes are serialized under Parallel.
+//The first write error stops the evaluation. Returns the first error of the writes or of the pipeline.
+func writeEach(pipeline *referencePipeline, write func(e types.T) error) error {
+	var mutex sync.Mutex
+	pipeline.evaluate(newDefaultTerminalStage(
+		acceptFunc(func(e types.T) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if pipeline.failed() {
+				return
+			}
+			if err := write(e); err != nil {
+				pipeline.fail(err)
+			}
+		}),
+		cancellationRequestedFunc(func() bool {
+			return pipeline.failed()
+		}),
+	))
+	return pipeline.getErr()
+}
+
+//openReader Returns r decompressed as configured by o. The returned reader closes r if r is an io.Closer.
+func openReader(r io.Reader, o *ioOptions) io.Reader {
+	if !o.gzip {
+		return r
+	}
+	return &gzipReader{r: r}
+}
+
+//openWriter Returns w compressed as configured by o, and the function flushing the compressed output, which
+//does not close w.
+func openWriter(w io.Writer, o *ioOptions) (io.Writer, func() error) {
+	if !o.gzip {
+		return w, func() error {
+			return nil
+		}
+	}
+	zw := gzip.NewWriter(w)
+	return zw, zw.Close
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+//gzipReader Decompresses r by gzip, the gzip header is read by the first Read.
+//If detect is true, r is only decompressed if it starts with the gzip magic bytes.
+type gzipReader struct {
+	r      io.Reader
+	detect bool
+	zr     *gzip.Reader
+	inner  io.Reader
+	err    error
+}
+
+func (g *gzipReader) Read(p []byte) (int, error) {
+	if g.inner == nil && g.err == nil {
+		br := bufio.NewReader(g.r)
+		if magic, _ := br.Peek(len(gzipMagic)); g.detect && !bytes.Equal(magic, gzipMagic) {
+			g.inner = br
+		} else if g.zr, g.err = gzip.NewReader(br); g.err == nil {
+			g.inner = g.zr
+		}
+	}
+	if g.err != nil {
+		return 0, g.err
+	}
+	return g.inner.Read(p)
+}
+
+func (g *gzipReader) Close() error {
+	var err error
+	if g.zr != nil {
+		err = g.zr.Close()
+	}
+	if closer, ok := g.r.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+//closeWriter Flush the output of a terminal operation that ended with err, returns the first error.
+func closeWriter(err error, flush func() error) error {
+	if flushErr := flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,105 @@
+package stream
+
+import (
+	"bufio"
+	"errors"
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+//trackingReader Counts the reads and records whether it has been closed, err is returned once the text is read.
+type trackingReader struct {
+	r      io.Reader
+	reads  int
+	closed bool
+	err    error
+}
+
+func newTrackingReader(text string) *trackingReader {
+	return &trackingReader{r: strings.NewReader(text)}
+}
+
+func (r *trackingReader) Read(p []byte) (int, error) {
+	r.reads++
+	n, err := r.r.Read(p)
+	if err == io.EOF && r.err != nil {
+		return n, r.err
+	}
+	return n, err
+}
+
+func (r *trackingReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestOfLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		opts   []IOOption
+		actual []types.T
+	}{
+		{
+			name:   "linesCase",
+			text:   "a\nb\r\n\nc",
+			actual: []types.T{"a", "b", "", "c"},
+		},
+		{
+			name:   "splitCase",
+			text:   "a b\n c ",
+			opts:   []IOOption{WithSplit(bufio.ScanWords)},
+			actual: []types.T{"a", "b", "c"},
+		},
+		{
+			name:   "emptyCase",
+			text:   "",
+			actual: []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := newTrackingReader(test.text)
+			s := OfLines(r, test.opts...)
+			assert.Equal(t, test.actual, s.ToSlice())
+			assert.NoError(t, s.Err())
+			assert.True(t, r.closed)
+		})
+	}
+
+	t.Run("lazyCase", func(t *testing.T) {
+		r := newTrackingReader("a\nb\nc\n")
+		s := OfLines(r)
+		assert.Equal(t, 0, r.reads)
+		assert.Equal(t, []types.T{"a"}, s.Limit(1).ToSlice())
+		assert.True(t, r.closed)
+	})
+
+	t.Run("maxTokenSizeCase", func(t *testing.T) {
+		s := OfLines(strings.NewReader("short\ntoo long line\n"), WithMaxTokenSize(8))
+		assert.Equal(t, []types.T{"short"}, s.ToSlice())
+		assert.Equal(t, bufio.ErrTooLong, s.Err())
+	})
+
+	t.Run("readErrCase", func(t *testing.T) {
+		r := newTrackingReader("a\nb\n")
+		r.err = errors.New("disk failed")
+		s := OfLines(r)
+		assert.Equal(t, 2, s.Count())
+		assert.EqualError(t, s.Err(), "disk failed")
+		assert.True(t, r.closed)
+	})
+}
+
+func TestOfScanner(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("go stream go"))
+	scanner.Split(bufio.ScanWords)
+	actual := OfScanner(scanner).Distinct(func(item types.T) types.R {
+		return item
+	}).ToSlice()
+	assert.Equal(t, []types.T{"go", "stream"}, actual)
+}
@@ -15,6 +15,20 @@ type iterator interface {
 	Next() types.T
 }
 
+//closableIterator Is an iterator holding resources such as files or goroutines.
+//Close is called once the pipeline has been evaluated, including when the evaluation is short-circuited.
+type closableIterator interface {
+	iterator
+	Close() error
+}
+
+//errIterator Is an iterator that can fail, HasNext returns false after a failure and Err returns the error.
+//The error is recorded by the pipeline once it has been evaluated.
+type errIterator interface {
+	iterator
+	Err() error
+}
+
 //iteratorBaseInfo The basic information of an iterator.
 type iteratorBaseInfo struct {
 	currentIndex int
@@ -90,6 +104,97 @@ func (iterator *supplierIterator) Next() types.T {
 	return iterator.get()
 }
 
+//rangeIterator A general type iterator of ints from start by step, size is the number of ints.
+type rangeIterator struct {
+	*iteratorBaseInfo
+	start int
+	step  int
+}
+
+func (iterator *rangeIterator) Next() types.T {
+	element := iterator.start + iterator.currentIndex*iterator.step
+	iterator.currentIndex++
+	return element
+}
+
+//iterateIterator A general type infinite iterator of seed, next(seed), next(next(seed)) and so on.
+type iterateIterator struct {
+	*iteratorInfiniteBaseInfo
+	current types.T
+	started bool
+	next    func(e types.T) types.T
+}
+
+func (iterator *iterateIterator) Next() types.T {
+	if iterator.started {
+		iterator.current = iterator.next(iterator.current)
+	}
+	iterator.started = true
+	return iterator.current
+}
+
+//iterateWhileIterator A general type iterator of seed, next(seed), next(next(seed)) and so on, as long as
+//hasNext returns true for the element. The size is unknown.
+type iterateWhileIterator struct {
+	current types.T
+	started bool
+	hasNext func(e types.T) bool
+	next    func(e types.T) types.T
+}
+
+func (iterator *iterateWhileIterator) GetSize() int {
+	return -1
+}
+
+func (iterator *iterateWhileIterator) HasNext() bool {
+	if iterator.started {
+		iterator.current = iterator.next(iterator.current)
+		iterator.started = false
+	}
+	return iterator.hasNext(iterator.current)
+}
+
+func (iterator *iterateWhileIterator) Next() types.T {
+	iterator.started = true
+	return iterator.current
+}
+
+//unfoldIterator A general type iterator producing elements from a state, fn returns the element, the next state
+//and false once there are no more elements. The size is unknown.
+type unfoldIterator struct {
+	state   types.T
+	value   types.T
+	hasNext bool
+	done    bool
+	fn      func(state types.T) (value types.T, nextState types.T, ok bool)
+}
+
+func (iterator *unfoldIterator) GetSize() int {
+	return -1
+}
+
+func (iterator *unfoldIterator) HasNext() bool {
+	if iterator.hasNext {
+		return true
+	}
+	if iterator.done {
+		return false
+	}
+	value, nextState, ok := iterator.fn(iterator.state)
+	if !ok {
+		iterator.done = true
+		return false
+	}
+	iterator.value, iterator.state, iterator.hasNext = value, nextState, true
+	return true
+}
+
+func (iterator *unfoldIterator) Next() types.T {
+	iterator.HasNext()
+	iterator.hasNext = false
+	return iterator.value
+}
+
 //build iterator
 
 func buildSliceIterator(elements ...types.T) iterator {
@@ -128,3 +233,37 @@ func buildSupplierIterator(fn func() types.T) iterator {
 		get:                      fn,
 	}
 }
+
+func buildRangeIterator(start int, size int, step int) iterator {
+	return &rangeIterator{
+		iteratorBaseInfo: &iteratorBaseInfo{
+			currentIndex: 0,
+			size:         size,
+		},
+		start: start,
+		step:  step,
+	}
+}
+
+func buildIterateIterator(seed types.T, next func(e types.T) types.T) iterator {
+	return &iterateIterator{
+		iteratorInfiniteBaseInfo: &iteratorInfiniteBaseInfo{},
+		current:                  seed,
+		next:                     next,
+	}
+}
+
+func buildIterateWhileIterator(seed types.T, hasNext func(e types.T) bool, next func(e types.T) types.T) iterator {
+	return &iterateWhileIterator{
+		current: seed,
+		hasNext: hasNext,
+		next:    next,
+	}
+}
+
+func buildUnfoldIterator(state types.T, fn func(state types.T) (value types.T, nextState types.T, ok bool)) iterator {
+	return &unfoldIterator{
+		state: state,
+		fn:    fn,
+	}
+}
@@ -121,3 +121,59 @@ func TestSupplierIterator(t *testing.T) {
 		})
 	}
 }
+
+func TestRangeIterator(t *testing.T) {
+	testCases := []func(it iterator){
+		func(it iterator) {
+			size := it.GetSize()
+			assert.Equal(t, 4, size)
+		},
+		func(it iterator) {
+			for i := 10; i > 2; i -= 2 {
+				e := it.Next()
+				assert.Equal(t, i, e)
+			}
+		},
+		func(it iterator) {
+			hasNext := it.HasNext()
+			assert.Equal(t, false, hasNext)
+		},
+	}
+
+	rangeIterator := buildRangeIterator(10, 4, -2)
+	for _, testCase := range testCases {
+		t.Run("testCase", func(t *testing.T) {
+			testCase(rangeIterator)
+		})
+	}
+}
+
+func TestUnfoldIterator(t *testing.T) {
+	testCases := []func(it iterator){
+		func(it iterator) {
+			size := it.GetSize()
+			assert.Equal(t, -1, size)
+		},
+		func(it iterator) {
+			for i := 0; i < 3; i++ {
+				assert.Equal(t, true, it.HasNext())
+				assert.Equal(t, true, it.HasNext())
+				assert.Equal(t, i*i, it.Next())
+			}
+		},
+		func(it iterator) {
+			hasNext := it.HasNext()
+			assert.Equal(t, false, hasNext)
+		},
+	}
+
+	unfoldIterator := buildUnfoldIterator(0, func(state types.T) (types.T, types.T, bool) {
+		i := state.(int)
+		return i * i, i + 1, i < 3
+	})
+	for _, testCase := range testCases {
+		t.Run("testCase", func(t *testing.T) {
+			testCase(unfoldIterator)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package stream
+
+import (
+	"github.com/chinalhr/go-stream/types"
+	"sync"
+)
+
+//JoinType Is the type of a join between two streams.
+type JoinType int
+
+const (
+	//JoinInner Emits the pairs of left and right elements that have the same key.
+	JoinInner JoinType = iota
+	//JoinLeft Emits the pairs of JoinInner, and the left elements without a matching right element paired with nil.
+	JoinLeft
+	//JoinFullOuter Emits the pairs of JoinLeft, and the right elements without a matching left element paired with
+	//nil after all left elements.
+	JoinFullOuter
+)
+
+//Join operation
+//The joins are hash joins: the right stream is the build side, it is evaluated and materialized into a hash table
+//when the terminal operation begins, and the elements of this Stream, the probe side, are streamed through it.
+//Keys are compared by ==, an error of the right stream is returned by Err.
+
+//InnerJoin Returns a Stream of types.KV pairs with the left element in KEY and the right element in VALUE,
+//for the left and right elements that have the same key.
+func (s Stream) InnerJoin(right Stream, leftKey func(e types.T) types.K, rightKey func(e types.T) types.K) Stream {
+	return s.Join(right, JoinInner, leftKey, rightKey, pairKV)
+}
+
+//LeftJoin Returns a Stream of types.KV pairs like InnerJoin, the left elements without a matching right element are
+//paired with nil.
+func (s Stream) LeftJoin(right Stream, leftKey func(e types.T) types.K, rightKey func(e types.T) types.K) Stream {
+	return s.Join(right, JoinLeft, leftKey, rightKey, pairKV)
+}
+
+//FullOuterJoin Returns a Stream of types.KV pairs like LeftJoin, followed by the right elements without a matching
+//left element paired with a nil left element.
+func (s Stream) FullOuterJoin(right Stream, leftKey func(e types.T) types.K, rightKey func(e types.T) types.K) Stream {
+	return s.Join(right, JoinFullOuter, leftKey, rightKey, pairKV)
+}
+
+//Join Returns a Stream of the pairs of left and right elements of joinType, combined by the combiner function.
+func (s Stream) Join(right Stream, joinType JoinType, leftKey func(e types.T) types.K, rightKey func(e types.T) types.K,
+	combiner func(left types.T, right types.T) types.R) Stream {
+	pipeline := s.p
+	pipeline.addOperation(func(next stage) stage {
+		var table *orderedGroups
+		var matched map[types.K]bool
+		var mutex sync.Mutex
+		return newDefaultIntermediateStage(next, beginFunc(func(size int) {
+			if table == nil {
+				table = buildHashTable(pipeline, right, rightKey)
+				matched = make(map[types.K]bool)
+			}
+			next.Begin(-1)
+		}), acceptFunc(func(e types.T) {
+			key := leftKey(e)
+			rows, ok := table.elements[key]
+			if !ok {
+				if joinType != JoinInner {
+					next.Accept(combiner(e, nil))
+				}
+				return
+			}
+			if joinType == JoinFullOuter {
+				mutex.Lock()
+				matched[key] = true
+				mutex.Unlock()
+			}
+			for i := 0; i < len(rows) && !next.CancellationRequested(); i++ {
+				next.Accept(combiner(e, rows[i]))
+			}
+		}), endFunc(func() {
+			if joinType == JoinFullOuter {
+				table.each(func(key types.K, index int, e types.T) bool {
+					if next.CancellationRequested() {
+						return false
+					}
+					if !matched[key] {
+						next.Accept(combiner(nil, e))
+					}
+					return true
+				})
+			}
+			table, matched = nil, nil
+			next.End()
+		}))
+	})
+	return s
+}
+
+//SemiJoin Returns a Stream of the left elements that have a matching right element, each left element is emitted
+//at most once.
+func (s Stream) SemiJoin(right Stream, leftKey func(e types.T) types.K, rightKey func(e types.T) types.K) Stream {
+	return s.filterByHashTable(right, leftKey, rightKey, true)
+}
+
+//AntiJoin Returns a Stream of the left elements that have no matching right element.
+func (s Stream) AntiJoin(right Stream, leftKey func(e types.T) types.K, rightKey func(e types.T) types.K) Stream {
+	return s.filterByHashTable(right, leftKey, rightKey, false)
+}
+
+func (s Stream) filterByHashTable(right Stream, leftKey func(e types.T) types.K, rightKey func(e types.T) types.K,
+	match bool) Stream {
+	pipeline := s.p
+	pipeline.addOperation(func(next stage) stage {
+		var table *orderedGroups
+		return newDefaultIntermediateStage(next, beginFunc(func(size int) {
+			if table == nil {
+				table = buildHashTable(pipeline, right, rightKey)
+			}
+			next.Begin(-1)
+		}), acceptFunc(func(e types.T) {
+			if _, ok := table.elements[leftKey(e)]; ok == match {
+				next.Accept(e)
+			}
+		}), endFunc(func() {
+			table = nil
+			next.End()
+		}))
+	})
+	pipeline.keepSorted()
+	return s
+}
+
+func pairKV(left types.T, right types.T) types.R {
+	return types.KV{KEY: left, VALUE: right}
+}
+
+//buildHashTable Evaluate the build stream and group its elements by the key calculated by keyFn, keeping the
+//encounter order of the elements. An error of the build stream is recorded by the probe pipeline.
+//The build stream can only be evaluated once, so the stages build the table by the first Begin, an upstream sort
+//calls Begin again once its elements are sorted.
+func buildHashTable(probe *referencePipeline, build Stream, keyFn func(e types.T) types.K) *orderedGroups {
+	table := groupStream(build, keyFn, true)
+	if err := build.Err(); err != nil {
+		probe.fail(err)
+	}
+	return table
+}
@@ -0,0 +1,116 @@
+package stream
+
+import (
+	"fmt"
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type customer struct {
+	id   int
+	name string
+}
+
+type order struct {
+	id         int
+	customerID int
+}
+
+func TestStream_Join(t *testing.T) {
+	customers := func() Stream {
+		return OfElements(customer{1, "alice"}, customer{2, "bob"}, customer{3, "carol"})
+	}
+	orders := func() Stream {
+		return OfElements(order{10, 1}, order{11, 4}, order{12, 1}, order{13, 2})
+	}
+	orderKey := func(e types.T) types.K {
+		return e.(order).customerID
+	}
+	customerKey := func(e types.T) types.K {
+		return e.(customer).id
+	}
+
+	tests := []struct {
+		name   string
+		stream Stream
+		actual []types.T
+	}{
+		{
+			name:   "innerJoinCase",
+			stream: orders().InnerJoin(customers(), orderKey, customerKey),
+			actual: []types.T{
+				types.KV{KEY: order{10, 1}, VALUE: customer{1, "alice"}},
+				types.KV{KEY: order{12, 1}, VALUE: customer{1, "alice"}},
+				types.KV{KEY: order{13, 2}, VALUE: customer{2, "bob"}},
+			},
+		},
+		{
+			name:   "leftJoinCase",
+			stream: orders().LeftJoin(customers(), orderKey, customerKey),
+			actual: []types.T{
+				types.KV{KEY: order{10, 1}, VALUE: customer{1, "alice"}},
+				types.KV{KEY: order{11, 4}, VALUE: nil},
+				types.KV{KEY: order{12, 1}, VALUE: customer{1, "alice"}},
+				types.KV{KEY: order{13, 2}, VALUE: customer{2, "bob"}},
+			},
+		},
+		{
+			name:   "fullOuterJoinCase",
+			stream: orders().FullOuterJoin(customers(), orderKey, customerKey),
+			actual: []types.T{
+				types.KV{KEY: order{10, 1}, VALUE: customer{1, "alice"}},
+				types.KV{KEY: order{11, 4}, VALUE: nil},
+				types.KV{KEY: order{12, 1}, VALUE: customer{1, "alice"}},
+				types.KV{KEY: order{13, 2}, VALUE: customer{2, "bob"}},
+				types.KV{KEY: nil, VALUE: customer{3, "carol"}},
+			},
+		},
+		{
+			name: "combinerCase",
+			stream: orders().Join(customers(), JoinInner, orderKey, customerKey, func(left types.T, right types.T) types.R {
+				return fmt.Sprintf("%d:%s", left.(order).id, right.(customer).name)
+			}),
+			actual: []types.T{"10:alice", "12:alice", "13:bob"},
+		},
+		{
+			name:   "semiJoinCase",
+			stream: customers().SemiJoin(orders(), customerKey, orderKey),
+			actual: []types.T{customer{1, "alice"}, customer{2, "bob"}},
+		},
+		{
+			name:   "antiJoinCase",
+			stream: customers().AntiJoin(orders(), customerKey, orderKey),
+			actual: []types.T{customer{3, "carol"}},
+		},
+		{
+			name: "afterSortCase",
+			stream: orders().Sorted(func(first types.T, second types.T) int {
+				return second.(order).id - first.(order).id
+			}).InnerJoin(customers(), orderKey, customerKey),
+			actual: []types.T{
+				types.KV{KEY: order{13, 2}, VALUE: customer{2, "bob"}},
+				types.KV{KEY: order{12, 1}, VALUE: customer{1, "alice"}},
+				types.KV{KEY: order{10, 1}, VALUE: customer{1, "alice"}},
+			},
+		},
+		{
+			name: "semiJoinAfterSortCase",
+			stream: customers().Sorted(func(first types.T, second types.T) int {
+				return second.(customer).id - first.(customer).id
+			}).SemiJoin(orders(), customerKey, orderKey),
+			actual: []types.T{customer{2, "bob"}, customer{1, "alice"}},
+		},
+		{
+			name:   "emptyBuildCase",
+			stream: orders().InnerJoin(OfElements(), orderKey, customerKey),
+			actual: []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.actual, test.stream.ToSlice())
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package stream
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/chinalhr/go-stream/types"
+	"io"
+	"strings"
+)
+
+//JSONDecodeError Is the error of a JSON value that can not be decoded, Line is the 1-based line of the input
+//where the error occurred.
+type JSONDecodeError struct {
+	Line int
+	Err  error
+}
+
+func (e *JSONDecodeError) Error() string {
+	return fmt.Sprintf("json: line %d: %v", e.Line, e.Err)
+}
+
+func (e *JSONDecodeError) Unwrap() error {
+	return e.Err
+}
+
+//OfJSONLines Return a sequential Stream of the JSON values of r, one value per line, blank lines are ignored.
+//newElem returns a pointer to a new value that a line is decoded into, such as a struct, the element is the value
+//the pointer points to. If newElem is nil, lines are decoded as interface{} values, objects as
+//map[string]interface{}. A line that can not be decoded fails the Stream with a *JSONDecodeError returned by Err,
+//unless SkipBadRecords is given. r is read lazily and closed like OfLines, WithMaxTokenSize limits the line size.
+func OfJSONLines(r io.Reader, newElem func() types.T, opts ...IOOption) Stream {
+	o := newIOOptions(opts)
+	o.split = bufio.ScanLines
+	pipeline := newPipeline(&jsonLinesIterator{
+		lines:   buildLinesIterator(r, o),
+		newElem: newElem,
+		skipBad: o.skipBad,
+	})
+	return Stream{pipeline}
+}
+
+//OfJSONArray Return a sequential Stream of the values of the JSON array read from r, such as written by
+//ToJSONArray, the array is decoded value by value without reading it into memory. newElem is used like in
+//OfJSONLines. A malformed array fails the Stream with a *JSONDecodeError returned by Err, SkipBadRecords skips the
+//values that are well-formed JSON but can not be decoded into newElem. If r is an io.Closer, it is closed once the
+//Stream has been evaluated.
+func OfJSONArray(r io.Reader, newElem func() types.T, opts ...IOOption) Stream {
+	o := newIOOptions(opts)
+	r = openReader(r, o)
+	counter := &lineCounter{r: r}
+	pipeline := newPipeline(&jsonArrayIterator{
+		dec:     json.NewDecoder(counter),
+		counter: counter,
+		reader:  r,
+		newElem: newElem,
+		skipBad: o.skipBad,
+	})
+	return Stream{pipeline}
+}
+
+//ToJSONLines Write the elements as JSON values to w, one value per line.
+//Returns the first error of encoding, writing or evaluating the Stream.
+func (s Stream) ToJSONLines(w io.Writer, opts ...IOOption) error {
+	out, flush := openWriter(w, newIOOptions(opts))
+	enc := json.NewEncoder(out)
+	err := writeEach(s.p, func(e types.T) error {
+		return enc.Encode(e)
+	})
+	return closeWriter(err, flush)
+}
+
+//ToJSONArray Write the elements to w as a JSON array, one value per line.
+//Returns the first error of encoding, writing or evaluating the Stream.
+func (s Stream) ToJSONArray(w io.Writer, opts ...IOOption) error {
+	out, flush := openWriter(w, newIOOptions(opts))
+	separator := "[\n"
+	err := writeEach(s.p, func(e types.T) error {
+		value, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(out, separator); err != nil {
+			return err
+		}
+		separator = ",\n"
+		_, err = out.Write(value)
+		return err
+	})
+	if err == nil {
+		if separator == "[\n" {
+			_, err = io.WriteString(out, "[]\n")
+		} else {
+			_, err = io.WriteString(out, "\n]\n")
+		}
+	}
+	return closeWriter(err, flush)
+}
+
+//jsonLinesIterator A general type iterator decoding the lines of a scannerIterator as JSON values.
+type jsonLinesIterator struct {
+	iteratorInfiniteBaseInfo
+	lines   *scannerIterator
+	newElem func() types.T
+	skipBad bool
+	line    int
+	next    types.T
+	hasNext bool
+	err     error
+}
+
+func (it *jsonLinesIterator) HasNext() bool {
+	if it.hasNext {
+		return true
+	}
+	for it.err == nil && it.lines.HasNext() {
+		text := it.lines.Next().(string)
+		it.line++
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		e, err := decodeJSON(func(v interface{}) error {
+			return json.Unmarshal([]byte(text), v)
+		}, it.newElem)
+		if err != nil {
+			if !it.skipBad {
+				it.err = &JSONDecodeError{Line: it.line, Err: err}
+			}
+			continue
+		}
+		it.next, it.hasNext = e, true
+		return true
+	}
+	return false
+}
+
+func (it *jsonLinesIterator) Next() types.T {
+	it.HasNext()
+	e := it.next
+	it.next, it.hasNext = nil, false
+	return e
+}
+
+func (it *jsonLinesIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.lines.Err()
+}
+
+func (it *jsonLinesIterator) Close() error {
+	return it.lines.Close()
+}
+
+//jsonArrayIterator A general type iterator decoding the values of a JSON array one by one.
+type jsonArrayIterator struct {
+	iteratorInfiniteBaseInfo
+	dec     *json.Decoder
+	counter *lineCounter
+	reader  io.Reader
+	newElem func() types.T
+	skipBad bool
+	started bool
+	done    bool
+	closed  bool
+	next    types.T
+	hasNext bool
+	err     error
+}
+
+func (it *jsonArrayIterator) HasNext() bool {
+	if it.hasNext {
+		return true
+	}
+	if it.done {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		if !it.expectDelim('[') {
+			return false
+		}
+	}
+	for it.dec.More() {
+		start := it.dec.InputOffset()
+		e, err := decodeJSON(it.dec.Decode, it.newElem)
+		if err == nil {
+			it.counter.discard(it.dec.InputOffset())
+			it.next, it.hasNext = e, true
+			return true
+		}
+		var typeErr *json.UnmarshalTypeError
+		if !it.skipBad || !errors.As(err, &typeErr) {
+			it.failAt(err, start)
+			return false
+		}
+	}
+	it.expectDelim(']')
+	it.done = true
+	return false
+}
+
+//expectDelim Read the next token of the array, which must be delim.
+func (it *jsonArrayIterator) expectDelim(delim json.Delim) bool {
+	token, err := it.dec.Token()
+	if err == nil && token != delim {
+		err = fmt.Errorf("expected %v, found %v", delim, token)
+	}
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		it.fail(err)
+		return false
+	}
+	return true
+}
+
+func (it *jsonArrayIterator) fail(err error) {
+	it.failAt(err, it.dec.InputOffset())
+}
+
+//failAt End the iterator with err, start is the input offset of the value being decoded, which the offset of a
+//json.UnmarshalTypeError is relative to.
+func (it *jsonArrayIterator) failAt(err error, start int64) {
+	offset := it.dec.InputOffset()
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = start + typeErr.Offset
+	}
+	it.err = &JSONDecodeError{Line: it.counter.lineAt(offset), Err: err}
+	it.done = true
+}
+
+func (it *jsonArrayIterator) Next() types.T {
+	it.HasNext()
+	e := it.next
+	it.next, it.hasNext = nil, false
+	return e
+}
+
+func (it *jsonArrayIterator) Err() error {
+	return it.err
+}
+
+func (it *jsonArrayIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	it.done = true
+	if closer, ok := it.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+//lineCounter Tracks the line numbers of the bytes read from r. The offsets of the newlines that have not been
+//discarded are kept, lines is the count of the discarded ones.
+type lineCounter struct {
+	r        io.Reader
+	offset   int64
+	lines    int
+	newlines []int64
+}
+
+func (c *lineCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	for i := 0; i < n; i++ {
+		if p[i] == '\n' {
+			c.newlines = append(c.newlines, c.offset+int64(i))
+		}
+	}
+	c.offset += int64(n)
+	return n, err
+}
+
+//lineAt Returns the 1-based line of the byte at offset, offset must not be before the discarded newlines.
+func (c *lineCounter) lineAt(offset int64) int {
+	line := c.lines + 1
+	for _, newline := range c.newlines {
+		if newline >= offset {
+			break
+		}
+		line++
+	}
+	return line
+}
+
+//discard Forget the newlines before offset.
+func (c *lineCounter) discard(offset int64) {
+	i := 0
+	for i < len(c.newlines) && c.newlines[i] < offset {
+		i++
+	}
+	c.lines += i
+	c.newlines = c.newlines[i:]
+}
@@ -0,0 +1,111 @@
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type jsonEvent struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newJSONEvent() types.T {
+	return &jsonEvent{}
+}
+
+func TestOfJSONLines(t *testing.T) {
+	t.Run("structCase", func(t *testing.T) {
+		r := newTrackingReader("{\"id\":1,\"name\":\"a\"}\n\n{\"id\":2,\"name\":\"b\"}\n")
+		s := OfJSONLines(r, newJSONEvent)
+		assert.Equal(t, []types.T{jsonEvent{1, "a"}, jsonEvent{2, "b"}}, s.ToSlice())
+		assert.NoError(t, s.Err())
+		assert.True(t, r.closed)
+	})
+
+	t.Run("mapCase", func(t *testing.T) {
+		s := OfJSONLines(strings.NewReader(`{"id":1}`), nil)
+		assert.Equal(t, []types.T{map[string]interface{}{"id": float64(1)}}, s.ToSlice())
+	})
+
+	t.Run("badLineCase", func(t *testing.T) {
+		s := OfJSONLines(strings.NewReader("{\"id\":1}\n{\"id\":\n{\"id\":3}\n"), newJSONEvent)
+		assert.Equal(t, []types.T{jsonEvent{ID: 1}}, s.ToSlice())
+		var decodeErr *JSONDecodeError
+		assert.True(t, errors.As(s.Err(), &decodeErr))
+		assert.Equal(t, 2, decodeErr.Line)
+	})
+
+	t.Run("skipBadLineCase", func(t *testing.T) {
+		s := OfJSONLines(strings.NewReader("{\"id\":1}\n{\"id\":\"x\"}\nnot json\n{\"id\":3}\n"), newJSONEvent,
+			SkipBadRecords())
+		assert.Equal(t, []types.T{jsonEvent{ID: 1}, jsonEvent{ID: 3}}, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+}
+
+func TestOfJSONArray(t *testing.T) {
+	t.Run("structCase", func(t *testing.T) {
+		r := newTrackingReader(`[{"id":1,"name":"a"}, {"id":2,"name":"b"}, {"id":3}]`)
+		s := OfJSONArray(r, newJSONEvent)
+		assert.Equal(t, []types.T{jsonEvent{1, "a"}, jsonEvent{2, "b"}}, s.Limit(2).ToSlice())
+		assert.NoError(t, s.Err())
+		assert.True(t, r.closed)
+	})
+
+	t.Run("emptyCase", func(t *testing.T) {
+		s := OfJSONArray(strings.NewReader(" [ ] "), nil)
+		assert.Equal(t, []types.T{}, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+
+	t.Run("syntaxErrCase", func(t *testing.T) {
+		s := OfJSONArray(strings.NewReader("[\n{\"id\":1},\n{\"id\":2},\n{\"id\" 3}\n]"), newJSONEvent)
+		assert.Equal(t, 2, s.Count())
+		var decodeErr *JSONDecodeError
+		assert.True(t, errors.As(s.Err(), &decodeErr))
+		assert.Equal(t, 4, decodeErr.Line)
+	})
+
+	t.Run("typeErrCase", func(t *testing.T) {
+		input := "[\n{\"id\":1},\n{\"id\":2},\n{\"id\":3},\n{\"id\":\"x\"},\n{\"id\":5}\n]"
+		s := OfJSONArray(strings.NewReader(input), newJSONEvent)
+		assert.Equal(t, 3, s.Count())
+		var decodeErr *JSONDecodeError
+		assert.True(t, errors.As(s.Err(), &decodeErr))
+		assert.Equal(t, 5, decodeErr.Line)
+	})
+
+	t.Run("notArrayCase", func(t *testing.T) {
+		s := OfJSONArray(strings.NewReader(`{"id":1}`), newJSONEvent)
+		assert.Equal(t, 0, s.Count())
+		assert.Error(t, s.Err())
+	})
+
+	t.Run("skipBadValueCase", func(t *testing.T) {
+		s := OfJSONArray(strings.NewReader(`[{"id":1}, {"id":"x"}, {"id":3}]`), newJSONEvent, SkipBadRecords())
+		assert.Equal(t, []types.T{jsonEvent{ID: 1}, jsonEvent{ID: 3}}, s.ToSlice())
+		assert.NoError(t, s.Err())
+	})
+}
+
+func TestStream_ToJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	err := OfElements(jsonEvent{1, "a"}, jsonEvent{2, "b"}).ToJSONLines(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n", buf.String())
+
+	actual := OfJSONLines(&buf, newJSONEvent).ToSlice()
+	assert.Equal(t, []types.T{jsonEvent{1, "a"}, jsonEvent{2, "b"}}, actual)
+
+	t.Run("encodeErrCase", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := OfElements(1, func() {}, 3).ToJSONLines(&buf)
+		assert.Error(t, err)
+		assert.Equal(t, "1\n", buf.String())
+	})
+}
@@ -0,0 +1,276 @@
+package stream
+
+import (
+	"github.com/chinalhr/go-stream/types"
+	"math"
+)
+
+//IntStream is a Stream of int elements supporting numeric terminal operations, created by MapToInt.
+type IntStream struct {
+	s Stream
+}
+
+//FloatStream is a Stream of float64 elements supporting numeric terminal operations, created by MapToFloat.
+type FloatStream struct {
+	s Stream
+}
+
+//MapToInt Returns an IntStream of elements transformed by the mapper function.
+func (s Stream) MapToInt(mapper func(e types.T) int) IntStream {
+	return IntStream{s.Map(func(e types.T) (r types.R) {
+		return mapper(e)
+	})}
+}
+
+//MapToFloat Returns a FloatStream of elements transformed by the mapper function.
+func (s Stream) MapToFloat(mapper func(e types.T) float64) FloatStream {
+	return FloatStream{s.Map(func(e types.T) (r types.R) {
+		return mapper(e)
+	})}
+}
+
+//SummaryStatistics Collects the count, sum, min, max, mean and variance of numbers.
+//The mean and variance are maintained by Welford's algorithm, statistics collected by parallel workers are merged
+//by Chan's algorithm, so they stay numerically stable for large inputs.
+type SummaryStatistics struct {
+	count int
+	sum   float64
+	min   float64
+	max   float64
+	mean  float64
+	m2    float64
+}
+
+//Accept Add x to the statistics.
+func (st *SummaryStatistics) Accept(x float64) {
+	st.count++
+	st.sum += x
+	if st.count == 1 {
+		st.min, st.max = x, x
+	} else {
+		st.min, st.max = math.Min(st.min, x), math.Max(st.max, x)
+	}
+	delta := x - st.mean
+	st.mean += delta / float64(st.count)
+	st.m2 += delta * (x - st.mean)
+}
+
+//Merge Add the numbers collected by other to the statistics.
+func (st *SummaryStatistics) Merge(other SummaryStatistics) {
+	if other.count == 0 {
+		return
+	}
+	if st.count == 0 {
+		*st = other
+		return
+	}
+	count := st.count + other.count
+	delta := other.mean - st.mean
+	st.mean += delta * float64(other.count) / float64(count)
+	st.m2 += other.m2 + delta*delta*float64(st.count)*float64(other.count)/float64(count)
+	st.sum += other.sum
+	st.min, st.max = math.Min(st.min, other.min), math.Max(st.max, other.max)
+	st.count = count
+}
+
+//Count Returns the count of numbers.
+func (st SummaryStatistics) Count() int {
+	return st.count
+}
+
+//Sum Returns the sum of numbers.
+func (st SummaryStatistics) Sum() float64 {
+	return st.sum
+}
+
+//Min Returns the min number, or 0 if there are no numbers.
+func (st SummaryStatistics) Min() float64 {
+	return st.min
+}
+
+//Max Returns the max number, or 0 if there are no numbers.
+func (st SummaryStatistics) Max() float64 {
+	return st.max
+}
+
+//Mean Returns the arithmetic mean of numbers, or 0 if there are no numbers.
+func (st SummaryStatistics) Mean() float64 {
+	return st.mean
+}
+
+//Variance Returns the population variance of numbers, or 0 if there are no numbers.
+func (st SummaryStatistics) Variance() float64 {
+	if st.count == 0 {
+		return 0
+	}
+	return st.m2 / float64(st.count)
+}
+
+//StdDev Returns the population standard deviation of numbers.
+func (st SummaryStatistics) StdDev() float64 {
+	return math.Sqrt(st.Variance())
+}
+
+//IntStream terminal operation
+
+//Sum Returns the sum of elements in this IntStream.
+func (s IntStream) Sum() int {
+	return *collectCombining(s.s.p,
+		func() types.T {
+			return new(int)
+		},
+		func(acc types.T, e types.T) {
+			*acc.(*int) += e.(int)
+		},
+		func(acc types.T, other types.T) {
+			*acc.(*int) += *other.(*int)
+		}).(*int)
+}
+
+//Average Returns the arithmetic mean of elements in this IntStream, ok is false if the IntStream is empty.
+func (s IntStream) Average() (average float64, ok bool) {
+	st := s.SummaryStatistics()
+	return st.Mean(), st.Count() > 0
+}
+
+//Min Returns the min element in this IntStream, ok is false if the IntStream is empty.
+func (s IntStream) Min() (min int, ok bool) {
+	return s.extreme(func(e1 int, e2 int) bool {
+		return e1 < e2
+	})
+}
+
+//Max Returns the max element in this IntStream, ok is false if the IntStream is empty.
+func (s IntStream) Max() (max int, ok bool) {
+	return s.extreme(func(e1 int, e2 int) bool {
+		return e1 > e2
+	})
+}
+
+//Count Returns the count of elements in this IntStream.
+func (s IntStream) Count() int {
+	return s.s.Count()
+}
+
+//SummaryStatistics Returns the SummaryStatistics of elements in this IntStream.
+func (s IntStream) SummaryStatistics() SummaryStatistics {
+	return summarize(s.s.p, func(e types.T) float64 {
+		return float64(e.(int))
+	})
+}
+
+//Boxed Returns a Stream of the elements of this IntStream.
+func (s IntStream) Boxed() Stream {
+	return s.s
+}
+
+//extreme Returns the element that is better than all other elements.
+func (s IntStream) extreme(better func(e1 int, e2 int) bool) (int, bool) {
+	type optionalInt struct {
+		value int
+		ok    bool
+	}
+	result := collectCombining(s.s.p,
+		func() types.T {
+			return &optionalInt{}
+		},
+		func(acc types.T, e types.T) {
+			o := acc.(*optionalInt)
+			if !o.ok || better(e.(int), o.value) {
+				o.value, o.ok = e.(int), true
+			}
+		},
+		func(acc types.T, other types.T) {
+			o, p := acc.(*optionalInt), other.(*optionalInt)
+			if p.ok && (!o.ok || better(p.value, o.value)) {
+				*o = *p
+			}
+		}).(*optionalInt)
+	return result.value, result.ok
+}
+
+//FloatStream terminal operation
+
+//Sum Returns the sum of elements in this FloatStream.
+func (s FloatStream) Sum() float64 {
+	return s.SummaryStatistics().Sum()
+}
+
+//Average Returns the arithmetic mean of elements in this FloatStream, ok is false if the FloatStream is empty.
+func (s FloatStream) Average() (average float64, ok bool) {
+	st := s.SummaryStatistics()
+	return st.Mean(), st.Count() > 0
+}
+
+//Min Returns the min element in this FloatStream, ok is false if the FloatStream is empty.
+func (s FloatStream) Min() (min float64, ok bool) {
+	st := s.SummaryStatistics()
+	return st.Min(), st.Count() > 0
+}
+
+//Max Returns the max element in this FloatStream, ok is false if the FloatStream is empty.
+func (s FloatStream) Max() (max float64, ok bool) {
+	st := s.SummaryStatistics()
+	return st.Max(), st.Count() > 0
+}
+
+//Count Returns the count of elements in this FloatStream.
+func (s FloatStream) Count() int {
+	return s.s.Count()
+}
+
+//SummaryStatistics Returns the SummaryStatistics of elements in this FloatStream.
+func (s FloatStream) SummaryStatistics() SummaryStatistics {
+	return summarize(s.s.p, func(e types.T) float64 {
+		return e.(float64)
+	})
+}
+
+//Boxed Returns a Stream of the elements of this FloatStream.
+func (s FloatStream) Boxed() Stream {
+	return s.s
+}
+
+func summarize(pipeline *referencePipeline, toFloat func(e types.T) float64) SummaryStatistics {
+	return *collectCombining(pipeline,
+		func() types.T {
+			return &SummaryStatistics{}
+		},
+		func(acc types.T, e types.T) {
+			acc.(*SummaryStatistics).Accept(toFloat(e))
+		},
+		func(acc types.T, other types.T) {
+			acc.(*SummaryStatistics).Merge(*other.(*SummaryStatistics))
+		}).(*SummaryStatistics)
+}
+
+//collectCombining Evaluate the pipeline with an accumulator per worker created by supplier. An accumulator is
+//updated by one worker at a time, and the accumulators are merged into the first one by combiner at the end,
+//so the result does not depend on whether the pipeline is evaluated in parallel.
+func collectCombining(pipeline *referencePipeline, supplier func() types.T,
+	accumulator func(acc types.T, e types.T), combiner func(acc types.T, other types.T)) types.T {
+	workers := pipeline.workers
+	if workers < 1 {
+		workers = 1
+	}
+	var accumulators chan types.T
+	pipeline.evaluate(newDefaultTerminalStage(
+		beginFunc(func(size int) {
+			accumulators = make(chan types.T, workers)
+			for i := 0; i < workers; i++ {
+				accumulators <- supplier()
+			}
+		}),
+		acceptFunc(func(e types.T) {
+			acc := <-accumulators
+			accumulator(acc, e)
+			accumulators <- acc
+		}),
+	))
+
+	result := <-accumulators
+	for i := 1; i < workers; i++ {
+		combiner(result, <-accumulators)
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+package stream
+
+import (
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIntStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		input   []types.T
+		sum     int
+		average float64
+		min     int
+		max     int
+		ok      bool
+	}{
+		{
+			name:    "normalCase",
+			input:   []types.T{3, 1, 4, 1, 5},
+			sum:     14,
+			average: 2.8,
+			min:     1,
+			max:     5,
+			ok:      true,
+		},
+		{
+			name:    "parallelCase",
+			workers: 3,
+			input:   []types.T{3, 1, 4, 1, 5},
+			sum:     14,
+			average: 2.8,
+			min:     1,
+			max:     5,
+			ok:      true,
+		},
+		{
+			name:  "emptyCase",
+			input: []types.T{},
+		},
+		{
+			name:  "nilCase",
+			input: nil,
+		},
+	}
+
+	toInt := func(e types.T) int {
+		return e.(int)
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			of := func() IntStream {
+				return OfSlice(test.input).Parallel(test.workers).MapToInt(toInt)
+			}
+			assert.Equal(t, test.sum, of().Sum())
+			average, ok := of().Average()
+			assert.InDelta(t, test.average, average, 1e-9)
+			assert.Equal(t, test.ok, ok)
+			min, ok := of().Min()
+			assert.Equal(t, test.min, min)
+			assert.Equal(t, test.ok, ok)
+			max, ok := of().Max()
+			assert.Equal(t, test.max, max)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, len(test.input), of().Count())
+		})
+	}
+}
+
+func TestFloatStream(t *testing.T) {
+	input := make([]types.T, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		input = append(input, i)
+	}
+	toFloat := func(e types.T) float64 {
+		return 1e9 + float64(e.(int))
+	}
+
+	tests := []struct {
+		name    string
+		workers int
+	}{
+		{
+			name: "sequentialCase",
+		},
+		{
+			name:    "parallelCase",
+			workers: 8,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st := OfSlice(input).Parallel(test.workers).MapToFloat(toFloat).SummaryStatistics()
+			assert.Equal(t, 1000, st.Count())
+			assert.InDelta(t, 1e12+499500, st.Sum(), 1e-3)
+			assert.Equal(t, 1e9, st.Min())
+			assert.Equal(t, 1e9+999, st.Max())
+			assert.InDelta(t, 1e9+499.5, st.Mean(), 1e-6)
+			assert.InEpsilon(t, 83333.25, st.Variance(), 1e-9)
+			assert.InEpsilon(t, 288.6749902, st.StdDev(), 1e-9)
+
+			min, ok := OfSlice(input).Parallel(test.workers).MapToFloat(toFloat).Min()
+			assert.Equal(t, 1e9, min)
+			assert.Equal(t, true, ok)
+		})
+	}
+}
+
+func TestSummaryStatistics_Merge(t *testing.T) {
+	var all, first, second SummaryStatistics
+	for i, x := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		all.Accept(x)
+		if i < 3 {
+			first.Accept(x)
+		} else {
+			second.Accept(x)
+		}
+	}
+	first.Merge(second)
+	first.Merge(SummaryStatistics{})
+	assert.Equal(t, all.Count(), first.Count())
+	assert.InDelta(t, 5, first.Mean(), 1e-12)
+	assert.InDelta(t, 4, first.Variance(), 1e-12)
+	assert.InDelta(t, 2, first.StdDev(), 1e-12)
+	assert.Equal(t, 2.0, first.Min())
+	assert.Equal(t, 9.0, first.Max())
+}
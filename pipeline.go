@@ -8,18 +8,29 @@ import (
 //operation Represents an operation on the pipeline.
 //wrapStage stage wrapper function, pass parameters between successive stages by passing next stage.
 //preOpt Reference to the previous operation.
+//compare Is set by an operation that buffers and sorts all elements, a following Limit uses it to replace
+//the operation with a bounded heap.
+//sorted Marks that the elements flowing out of the operation are SORTED, a sorting operation downstream only
+//verifies the order and skips sorting if the elements are already in order.
+//ordered Marks that the operation needs the elements in encounter order, such as a stable sort.
 type operation struct {
 	wrapStage func(stage) stage
 	preOpt    *operation
+	compare   func(e1 types.T, e2 types.T) int
+	sorted    bool
+	ordered   bool
 }
 
 //referencePipeline
 //currentOpt is the latest intermediate operation in the pipeline.
 //workers is the number of parallel executions.
+//err is the first error reported by the stages of the pipeline.
 type referencePipeline struct {
 	it         iterator
 	currentOpt *operation
 	workers    int
+	err        error
+	errMutex   sync.Mutex
 }
 
 func newPipeline(source iterator) *referencePipeline {
@@ -42,18 +53,73 @@ func (p *referencePipeline) addOperation(wrap func(stage) stage) {
 	p.currentOpt = op
 }
 
+//keepSorted Mark the latest operation as SORTED if the previous operation is SORTED, used by operations that
+//neither reorder nor transform elements.
+func (p *referencePipeline) keepSorted() {
+	if p.currentOpt.preOpt != nil {
+		p.currentOpt.sorted = p.currentOpt.preOpt.sorted
+	}
+}
+
+//fail Record err as the error of the pipeline, only the first error is kept.
+func (p *referencePipeline) fail(err error) {
+	p.errMutex.Lock()
+	defer p.errMutex.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+//failed Returns true if an error has been recorded, stages use it to stop the data flow.
+func (p *referencePipeline) failed() bool {
+	return p.getErr() != nil
+}
+
+func (p *referencePipeline) getErr() error {
+	p.errMutex.Lock()
+	defer p.errMutex.Unlock()
+	return p.err
+}
+
 //evaluate the pipeline with a terminal operation to produce a result.
 //By passing terminalStage, the stage chain is constructed based on the pipeline based wrapStage method。
 //If the number of workers of Pipeline is greater than 1 and the size of pipeline Iterator is greater than 1,
 //will be parallel evaluate, otherwise it will be sequential evaluate.
+//A pipeline with an ordered operation is sequential evaluate, the workers deliver elements in no particular
+//order, the operation itself can still use the workers.
 func (p *referencePipeline) evaluate(terminalStage stage) {
-	if p.workers > 1 && p.it.GetSize() > 1 {
+	defer p.closeSource()
+	if p.workers > 1 && p.it.GetSize() > 1 && !p.ordered() {
 		p.evaluateParallel(terminalStage)
 	} else {
 		p.evaluateSequential(terminalStage)
 	}
 }
 
+//ordered Returns true if any operation of the pipeline needs the elements in encounter order.
+func (p *referencePipeline) ordered() bool {
+	for op := p.currentOpt; op != nil; op = op.preOpt {
+		if op.ordered {
+			return true
+		}
+	}
+	return false
+}
+
+//closeSource Record the error of the source iterator and close it once the pipeline has been evaluated.
+func (p *referencePipeline) closeSource() {
+	if it, ok := p.it.(errIterator); ok {
+		if err := it.Err(); err != nil {
+			p.fail(err)
+		}
+	}
+	if it, ok := p.it.(closableIterator); ok {
+		if err := it.Close(); err != nil {
+			p.fail(err)
+		}
+	}
+}
+
 func (p *referencePipeline) evaluateSequential(c stage) {
 	stage := c
 	for i := p.currentOpt; i.preOpt != nil; i = i.preOpt {
@@ -61,7 +127,7 @@ func (p *referencePipeline) evaluateSequential(c stage) {
 	}
 	source := p.it
 	stage.Begin(source.GetSize())
-	for source.HasNext() && !stage.CancellationRequested() {
+	for !stage.CancellationRequested() && source.HasNext() {
 		stage.Accept(source.Next())
 	}
 	stage.End()
@@ -0,0 +1,212 @@
+package predicate
+
+import (
+	"fmt"
+	"github.com/chinalhr/go-stream/internal/fields"
+	"github.com/chinalhr/go-stream/types"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+//Predicate A boolean function of an element with a readable name.
+//Test can be passed to Filter, AllMatch, AnyMatch, NoneMatch, TakeWhile and DropWhile, String returns the name,
+//composed predicates are named after their parts, for example "(IsNil OR color == red)".
+type Predicate struct {
+	name string
+	test func(e types.T) bool
+}
+
+//Of Returns a Predicate named name testing elements by test.
+func Of(name string, test func(e types.T) bool) Predicate {
+	return Predicate{
+		name: name,
+		test: test,
+	}
+}
+
+//Test Returns whether e matches the Predicate.
+func (p Predicate) Test(e types.T) bool {
+	return p.test(e)
+}
+
+//String Returns the name of the Predicate.
+func (p Predicate) String() string {
+	return p.name
+}
+
+//And Returns a Predicate matching elements that match all predicates, the predicates are tested in order and
+//the test stops at the first mismatch. And with no predicates matches every element.
+func And(predicates ...Predicate) Predicate {
+	return Predicate{
+		name: joinNames(predicates, " AND "),
+		test: func(e types.T) bool {
+			for _, p := range predicates {
+				if !p.test(e) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+}
+
+//Or Returns a Predicate matching elements that match any of predicates, the predicates are tested in order and
+//the test stops at the first match. Or with no predicates matches no element.
+func Or(predicates ...Predicate) Predicate {
+	return Predicate{
+		name: joinNames(predicates, " OR "),
+		test: func(e types.T) bool {
+			for _, p := range predicates {
+				if p.test(e) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+//Not Returns a Predicate matching elements that do not match p.
+func Not(p Predicate) Predicate {
+	return Predicate{
+		name: "NOT " + p.name,
+		test: func(e types.T) bool {
+			return !p.test(e)
+		},
+	}
+}
+
+//IsNil Returns a Predicate matching nil elements, nil pointers, maps, slices, channels and functions included.
+func IsNil() Predicate {
+	return Predicate{
+		name: "IsNil",
+		test: func(e types.T) bool {
+			return fields.IsNil(reflect.ValueOf(e))
+		},
+	}
+}
+
+//In Returns a Predicate matching elements equal to one of values, elements are compared by reflect.DeepEqual.
+func In(values ...types.T) Predicate {
+	hashable := make(map[types.T]bool)
+	others := make([]types.T, 0)
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		if isHashable(v) {
+			hashable[v] = true
+		} else {
+			others = append(others, v)
+		}
+		names = append(names, fmt.Sprint(v))
+	}
+
+	return Predicate{
+		name: "In(" + strings.Join(names, ", ") + ")",
+		test: func(e types.T) bool {
+			if isHashable(e) {
+				return hashable[e]
+			}
+			for _, v := range others {
+				if reflect.DeepEqual(e, v) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+//Between Returns a Predicate matching elements within [lo, hi] according to the compare function.
+func Between(lo types.T, hi types.T, compare func(first types.T, second types.T) int) Predicate {
+	return Predicate{
+		name: fmt.Sprintf("Between(%v, %v)", lo, hi),
+		test: func(e types.T) bool {
+			return compare(e, lo) >= 0 && compare(e, hi) <= 0
+		},
+	}
+}
+
+//FieldEquals Returns a Predicate matching struct elements whose field equals v, the elements can be structs or
+//pointers to structs. path is a field name, or a dot separated path of nested fields such as "Size.Weight",
+//names are matched case-insensitively if no field matches exactly, as by comparator.ByField.
+//Elements without the field, or with a nil pointer along the path, do not match.
+func FieldEquals(path string, v types.T) Predicate {
+	names := fields.Split(path)
+	return Predicate{
+		name: fmt.Sprintf("%s == %v", path, v),
+		test: func(e types.T) bool {
+			field, err := fields.ByPath(reflect.ValueOf(e), names)
+			if err != nil {
+				return false
+			}
+			return valueEquals(field, reflect.ValueOf(v))
+		},
+	}
+}
+
+//Matches Returns a Predicate matching elements whose text matches re, the text of an element is the element
+//itself for strings, the result of String for fmt.Stringer, and fmt.Sprint for other elements.
+func Matches(re *regexp.Regexp) Predicate {
+	return Predicate{
+		name: fmt.Sprintf("Matches(%s)", re),
+		test: func(e types.T) bool {
+			switch text := e.(type) {
+			case string:
+				return re.MatchString(text)
+			case fmt.Stringer:
+				return re.MatchString(text.String())
+			}
+			return re.MatchString(fmt.Sprint(e))
+		},
+	}
+}
+
+func joinNames(predicates []Predicate, sep string) string {
+	names := make([]string, 0, len(predicates))
+	for _, p := range predicates {
+		names = append(names, p.name)
+	}
+	return "(" + strings.Join(names, sep) + ")"
+}
+
+//isHashable Returns true for elements of builtin scalar kinds, which are used as map keys by In.
+func isHashable(e types.T) bool {
+	if e == nil {
+		return false
+	}
+	switch reflect.TypeOf(e).Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+//valueEquals Returns whether field equals v, unexported fields of scalar kinds are compared through reflect.
+func valueEquals(field reflect.Value, v reflect.Value) bool {
+	if field.CanInterface() {
+		if !v.IsValid() {
+			return fields.IsNil(field)
+		}
+		return reflect.DeepEqual(field.Interface(), v.Interface())
+	}
+	if !v.IsValid() || field.Type() != v.Type() {
+		return false
+	}
+	switch field.Kind() {
+	case reflect.Bool:
+		return field.Bool() == v.Bool()
+	case reflect.String:
+		return field.String() == v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int() == v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return field.Uint() == v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return field.Float() == v.Float()
+	}
+	return false
+}
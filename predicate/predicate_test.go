@@ -0,0 +1,114 @@
+package predicate
+
+import (
+	"github.com/chinalhr/go-stream"
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+type size struct {
+	Weight int
+}
+
+type widget struct {
+	color string
+	Size  *size
+}
+
+func TestPredicate(t *testing.T) {
+	even := Of("even", func(e types.T) bool {
+		return e.(int)%2 == 0
+	})
+	compare := func(first types.T, second types.T) int {
+		return first.(int) - second.(int)
+	}
+
+	tests := []struct {
+		name      string
+		predicate Predicate
+		input     []types.T
+		actual    []types.T
+		fullName  string
+	}{
+		{
+			name:      "andCase",
+			predicate: And(even, Between(2, 4, compare)),
+			input:     []types.T{1, 2, 3, 4, 5, 6},
+			actual:    []types.T{2, 4},
+			fullName:  "(even AND Between(2, 4))",
+		},
+		{
+			name:      "orCase",
+			predicate: Or(even, In(3, 5)),
+			input:     []types.T{1, 2, 3, 4, 5},
+			actual:    []types.T{2, 3, 4, 5},
+			fullName:  "(even OR In(3, 5))",
+		},
+		{
+			name:      "notCase",
+			predicate: Not(even),
+			input:     []types.T{1, 2, 3, 4, 5},
+			actual:    []types.T{1, 3, 5},
+			fullName:  "NOT even",
+		},
+		{
+			name:      "isNilCase",
+			predicate: IsNil(),
+			input:     []types.T{1, nil, (*widget)(nil), []int{}},
+			actual:    []types.T{nil, (*widget)(nil)},
+			fullName:  "IsNil",
+		},
+		{
+			name:      "inCase",
+			predicate: In([]int{1}, "a"),
+			input:     []types.T{[]int{1}, []int{2}, "a", "b", nil},
+			actual:    []types.T{[]int{1}, "a"},
+			fullName:  "In([1], a)",
+		},
+		{
+			name:      "fieldEqualsCase",
+			predicate: FieldEquals("color", "red"),
+			input:     []types.T{widget{color: "red"}, widget{color: "blue"}, &widget{color: "red"}, 1},
+			actual:    []types.T{widget{color: "red"}, &widget{color: "red"}},
+			fullName:  "color == red",
+		},
+		{
+			name:      "nestedFieldEqualsCase",
+			predicate: FieldEquals("Size.Weight", 2),
+			input:     []types.T{widget{Size: &size{2}}, widget{Size: &size{1}}, widget{}},
+			actual:    []types.T{widget{Size: &size{2}}},
+			fullName:  "Size.Weight == 2",
+		},
+		{
+			name:      "caseInsensitiveFieldEqualsCase",
+			predicate: FieldEquals("size.weight", 2),
+			input:     []types.T{widget{Size: &size{2}}, widget{Size: &size{1}}},
+			actual:    []types.T{widget{Size: &size{2}}},
+			fullName:  "size.weight == 2",
+		},
+		{
+			name:      "matchesCase",
+			predicate: Matches(regexp.MustCompile("^a+$")),
+			input:     []types.T{"aa", "ab", 1},
+			actual:    []types.T{"aa"},
+			fullName:  "Matches(^a+$)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := stream.OfSlice(test.input).
+				Filter(test.predicate.Test).
+				ToSlice()
+			assert.Equal(t, test.actual, result)
+			assert.Equal(t, test.fullName, test.predicate.String())
+		})
+	}
+}
+
+func TestPredicate_Empty(t *testing.T) {
+	assert.Equal(t, true, And().Test(1))
+	assert.Equal(t, false, Or().Test(1))
+}
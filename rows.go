@@ -0,0 +1,145 @@
+package stream
+
+import (
+	"database/sql"
+	"github.com/chinalhr/go-stream/types"
+	"reflect"
+	"strings"
+)
+
+//OfRows Return a sequential Stream of the rows of rows, each row is scanned lazily while the Stream is evaluated.
+//newDest returns a pointer to a new struct that a row is scanned into, the element is the struct it points to.
+//Columns are bound to the fields whose `db:"col"` tag names them, or to the untagged fields whose name equals
+//them ignoring case, `db:"-"` fields and unbound columns are ignored. If newDest is nil, a row is a
+//map[string]interface{} keyed by column. rows.Err and the scan errors are returned by Err, rows are closed once
+//the Stream has been evaluated, including when the evaluation is short-circuited by Limit or FindFirst.
+func OfRows(rows *sql.Rows, newDest func() types.T) Stream {
+	pipeline := newPipeline(&rowsIterator{
+		rows:    rows,
+		newDest: newDest,
+	})
+	return Stream{pipeline}
+}
+
+//rowsIterator A general type iterator scanning *sql.Rows, the columns are read by the first HasNext.
+type rowsIterator struct {
+	iteratorInfiniteBaseInfo
+	rows    *sql.Rows
+	newDest func() types.T
+	columns []string
+	fields  [][]int
+	next    types.T
+	hasNext bool
+	done    bool
+	err     error
+}
+
+func (it *rowsIterator) HasNext() bool {
+	if it.hasNext {
+		return true
+	}
+	if it.done {
+		return false
+	}
+	if !it.rows.Next() {
+		it.done = true
+		return false
+	}
+	e, err := it.scan()
+	if err != nil {
+		it.err, it.done = err, true
+		return false
+	}
+	it.next, it.hasNext = e, true
+	return true
+}
+
+//scan Scan the current row into a new struct returned by newDest, or into a map.
+func (it *rowsIterator) scan() (types.T, error) {
+	if it.columns == nil {
+		columns, err := it.rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		it.columns = columns
+	}
+	dest := make([]interface{}, len(it.columns))
+	if it.newDest == nil {
+		values := make([]interface{}, len(it.columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := it.rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(it.columns))
+		for i, column := range it.columns {
+			row[column] = values[i]
+		}
+		return row, nil
+	}
+
+	v := reflect.ValueOf(it.newDest()).Elem()
+	if it.fields == nil {
+		it.fields = dbFields(v.Type(), it.columns)
+	}
+	for i, field := range it.fields {
+		if field == nil {
+			dest[i] = new(interface{})
+			continue
+		}
+		dest[i] = v.FieldByIndex(field).Addr().Interface()
+	}
+	if err := it.rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+func (it *rowsIterator) Next() types.T {
+	it.HasNext()
+	e := it.next
+	it.next, it.hasNext = nil, false
+	return e
+}
+
+func (it *rowsIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.rows.Err()
+}
+
+func (it *rowsIterator) Close() error {
+	it.done = true
+	return it.rows.Close()
+}
+
+//dbFields Returns the index of the field of the struct type t bound to each column, or nil for unbound columns.
+func dbFields(t reflect.Type, columns []string) [][]int {
+	tagged := make(map[string][]int)
+	untagged := make(map[string][]int)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		switch name := field.Tag.Get("db"); name {
+		case "-":
+		case "":
+			untagged[strings.ToLower(field.Name)] = field.Index
+		default:
+			tagged[name] = field.Index
+		}
+	}
+
+	fields := make([][]int, len(columns))
+	for i, column := range columns {
+		if index, ok := tagged[column]; ok {
+			fields[i] = index
+		} else if index, ok := untagged[strings.ToLower(column)]; ok {
+			fields[i] = index
+		}
+	}
+	return fields
+}
@@ -0,0 +1,192 @@
+package stream
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"sync"
+	"testing"
+)
+
+//fakeTable Is the result of a query of the fake driver, err is returned after the rows.
+type fakeTable struct {
+	columns []string
+	rows    [][]driver.Value
+	err     error
+	closed  bool
+}
+
+//fakeDriver A database/sql driver answering every query with the fakeTable registered under the query text.
+type fakeDriver struct {
+	mutex  sync.Mutex
+	tables map[string]*fakeTable
+}
+
+var testDriver = &fakeDriver{tables: make(map[string]*fakeTable)}
+
+func init() {
+	sql.Register("stream-fake", testDriver)
+}
+
+func (d *fakeDriver) register(query string, table *fakeTable) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.tables[query] = table
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{driver: d}, nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.driver.mutex.Lock()
+	defer c.driver.mutex.Unlock()
+	table, ok := c.driver.tables[query]
+	if !ok {
+		return nil, errors.New("unknown query " + query)
+	}
+	return &fakeStmt{table: table}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type fakeStmt struct {
+	table *fakeTable
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec is not supported")
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{table: s.table}, nil
+}
+
+type fakeRows struct {
+	table *fakeTable
+	index int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.table.columns
+}
+
+func (r *fakeRows) Close() error {
+	r.table.closed = true
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.index == len(r.table.rows) {
+		if r.table.err != nil {
+			return r.table.err
+		}
+		return io.EOF
+	}
+	copy(dest, r.table.rows[r.index])
+	r.index++
+	return nil
+}
+
+type dbUser struct {
+	ID      int64  `db:"id"`
+	Name    string `db:"user_name"`
+	Email   string
+	Ignored string `db:"-"`
+}
+
+func queryFake(t *testing.T, table *fakeTable) *sql.Rows {
+	testDriver.register(t.Name(), table)
+	db, err := sql.Open("stream-fake", "")
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	rows, err := db.Query(t.Name())
+	assert.NoError(t, err)
+	return rows
+}
+
+func TestOfRows(t *testing.T) {
+	newTable := func() *fakeTable {
+		return &fakeTable{
+			columns: []string{"id", "user_name", "EMAIL", "created"},
+			rows: [][]driver.Value{
+				{int64(1), "ann", "ann@example.com", "x"},
+				{int64(2), "bob", "bob@example.com", "y"},
+				{int64(3), "cat", "cat@example.com", "z"},
+			},
+		}
+	}
+
+	t.Run("structCase", func(t *testing.T) {
+		table := newTable()
+		s := OfRows(queryFake(t, table), func() types.T {
+			return &dbUser{}
+		})
+		assert.Equal(t, []types.T{
+			dbUser{ID: 1, Name: "ann", Email: "ann@example.com"},
+			dbUser{ID: 2, Name: "bob", Email: "bob@example.com"},
+			dbUser{ID: 3, Name: "cat", Email: "cat@example.com"},
+		}, s.ToSlice())
+		assert.NoError(t, s.Err())
+		assert.True(t, table.closed)
+	})
+
+	t.Run("mapCase", func(t *testing.T) {
+		table := newTable()
+		first := OfRows(queryFake(t, table), nil).FindFirst()
+		assert.True(t, table.closed)
+		assert.Equal(t, map[string]interface{}{
+			"id": int64(1), "user_name": "ann", "EMAIL": "ann@example.com", "created": "x",
+		}, first)
+	})
+
+	t.Run("limitCase", func(t *testing.T) {
+		table := newTable()
+		s := OfRows(queryFake(t, table), nil)
+		assert.Equal(t, 2, s.Limit(2).Count())
+		assert.True(t, table.closed)
+	})
+
+	t.Run("rowsErrCase", func(t *testing.T) {
+		table := newTable()
+		table.err = errors.New("connection lost")
+		s := OfRows(queryFake(t, table), nil)
+		assert.Equal(t, 3, s.Count())
+		assert.EqualError(t, s.Err(), "connection lost")
+		assert.True(t, table.closed)
+	})
+
+	t.Run("scanErrCase", func(t *testing.T) {
+		table := newTable()
+		table.rows[1][0] = "two"
+		s := OfRows(queryFake(t, table), func() types.T {
+			return &dbUser{}
+		})
+		assert.Equal(t, 1, s.Count())
+		assert.Error(t, s.Err())
+		assert.True(t, table.closed)
+	})
+}
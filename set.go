@@ -0,0 +1,126 @@
+package stream
+
+import (
+	"github.com/chinalhr/go-stream/types"
+	"sync"
+)
+
+//Set operation
+//The set operations compare elements by the key calculated by keyFn, keys are compared by ==. The other stream is
+//evaluated and materialized when the terminal operation begins, the elements of this Stream are streamed and keep
+//their encounter order. The set variants emit each key at most once, the bag variants respect multiplicities.
+
+//Union Returns a Stream of the distinct elements of this Stream, followed by the distinct elements of other whose
+//key is not in this Stream.
+func (s Stream) Union(other Stream, keyFn func(e types.T) types.K) Stream {
+	return s.setOperation(other, keyFn, func(seen int, inOther int) bool {
+		return seen == 0
+	}, func(seen int, inThis int) bool {
+		return seen == 0 && inThis == 0
+	})
+}
+
+//Intersect Returns a Stream of the distinct elements of this Stream whose key is in other.
+func (s Stream) Intersect(other Stream, keyFn func(e types.T) types.K) Stream {
+	return s.setOperation(other, keyFn, func(seen int, inOther int) bool {
+		return seen == 0 && inOther > 0
+	}, nil)
+}
+
+//Except Returns a Stream of the distinct elements of this Stream whose key is not in other.
+func (s Stream) Except(other Stream, keyFn func(e types.T) types.K) Stream {
+	return s.setOperation(other, keyFn, func(seen int, inOther int) bool {
+		return seen == 0 && inOther == 0
+	}, nil)
+}
+
+//SymmetricDifference Returns a Stream of the distinct elements of this Stream whose key is not in other, followed by
+//the distinct elements of other whose key is not in this Stream.
+func (s Stream) SymmetricDifference(other Stream, keyFn func(e types.T) types.K) Stream {
+	return s.setOperation(other, keyFn, func(seen int, inOther int) bool {
+		return seen == 0 && inOther == 0
+	}, func(seen int, inThis int) bool {
+		return seen == 0 && inThis == 0
+	})
+}
+
+//UnionAll Returns a Stream of all elements of this Stream followed by all elements of other, the multiplicity of a
+//key is the sum of its multiplicities.
+func (s Stream) UnionAll(other Stream, keyFn func(e types.T) types.K) Stream {
+	return s.setOperation(other, keyFn, func(seen int, inOther int) bool {
+		return true
+	}, func(seen int, inThis int) bool {
+		return true
+	})
+}
+
+//IntersectAll Returns a Stream of the elements of this Stream whose key is in other, the multiplicity of a key is
+//the min of its multiplicities.
+func (s Stream) IntersectAll(other Stream, keyFn func(e types.T) types.K) Stream {
+	return s.setOperation(other, keyFn, func(seen int, inOther int) bool {
+		return seen < inOther
+	}, nil)
+}
+
+//ExceptAll Returns a Stream of the elements of this Stream, with as many elements of a key removed as other has,
+//the multiplicity of a key is the difference of its multiplicities. The later elements of a key are kept.
+func (s Stream) ExceptAll(other Stream, keyFn func(e types.T) types.K) Stream {
+	return s.setOperation(other, keyFn, func(seen int, inOther int) bool {
+		return seen >= inOther
+	}, nil)
+}
+
+//SymmetricDifferenceAll Returns a Stream of ExceptAll of this Stream and other, followed by ExceptAll of other and
+//this Stream, the multiplicity of a key is the absolute difference of its multiplicities.
+func (s Stream) SymmetricDifferenceAll(other Stream, keyFn func(e types.T) types.K) Stream {
+	return s.setOperation(other, keyFn, func(seen int, inOther int) bool {
+		return seen >= inOther
+	}, func(seen int, inThis int) bool {
+		return seen >= inThis
+	})
+}
+
+//setOperation Add a set operation to the pipeline. keepThis decides whether an element of this Stream is emitted,
+//keepOther decides whether an element of other is emitted after all elements of this Stream, or nil to emit no
+//element of other. seen is the number of the previous elements of the same key in the same stream, inOther is the
+//number of elements of the key in other, and inThis is the number of elements of the key in this Stream.
+func (s Stream) setOperation(other Stream, keyFn func(e types.T) types.K,
+	keepThis func(seen int, inOther int) bool, keepOther func(seen int, inThis int) bool) Stream {
+	pipeline := s.p
+	pipeline.addOperation(func(next stage) stage {
+		var table *orderedGroups
+		var thisCounts map[types.K]int
+		var mutex sync.Mutex
+		return newDefaultIntermediateStage(next, beginFunc(func(size int) {
+			if table == nil {
+				table = buildHashTable(pipeline, other, keyFn)
+				thisCounts = make(map[types.K]int)
+			}
+			next.Begin(-1)
+		}), acceptFunc(func(e types.T) {
+			key := keyFn(e)
+			mutex.Lock()
+			seen := thisCounts[key]
+			thisCounts[key]++
+			mutex.Unlock()
+			if keepThis(seen, len(table.elements[key])) {
+				next.Accept(e)
+			}
+		}), endFunc(func() {
+			if keepOther != nil {
+				table.each(func(key types.K, seen int, e types.T) bool {
+					if next.CancellationRequested() {
+						return false
+					}
+					if keepOther(seen, thisCounts[key]) {
+						next.Accept(e)
+					}
+					return true
+				})
+			}
+			table, thisCounts = nil, nil
+			next.End()
+		}))
+	})
+	return s
+}
@@ -0,0 +1,91 @@
+package stream
+
+import (
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStream_SetOperation(t *testing.T) {
+	identity := func(e types.T) types.K {
+		return e
+	}
+	left := func() Stream {
+		return OfElements(1, 2, 2, 3, 3, 3, 4)
+	}
+	right := func() Stream {
+		return OfElements(5, 3, 2, 3, 5)
+	}
+
+	tests := []struct {
+		name   string
+		stream Stream
+		actual []types.T
+	}{
+		{
+			name:   "unionCase",
+			stream: left().Union(right(), identity),
+			actual: []types.T{1, 2, 3, 4, 5},
+		},
+		{
+			name:   "intersectCase",
+			stream: left().Intersect(right(), identity),
+			actual: []types.T{2, 3},
+		},
+		{
+			name:   "exceptCase",
+			stream: left().Except(right(), identity),
+			actual: []types.T{1, 4},
+		},
+		{
+			name:   "symmetricDifferenceCase",
+			stream: left().SymmetricDifference(right(), identity),
+			actual: []types.T{1, 4, 5},
+		},
+		{
+			name:   "unionAllCase",
+			stream: left().UnionAll(right(), identity),
+			actual: []types.T{1, 2, 2, 3, 3, 3, 4, 5, 3, 2, 3, 5},
+		},
+		{
+			name:   "intersectAllCase",
+			stream: left().IntersectAll(right(), identity),
+			actual: []types.T{2, 3, 3},
+		},
+		{
+			name:   "exceptAllCase",
+			stream: left().ExceptAll(right(), identity),
+			actual: []types.T{1, 2, 3, 4},
+		},
+		{
+			name:   "symmetricDifferenceAllCase",
+			stream: left().SymmetricDifferenceAll(right(), identity),
+			actual: []types.T{1, 2, 3, 4, 5, 5},
+		},
+		{
+			name: "afterSortCase",
+			stream: left().Sorted(func(first types.T, second types.T) int {
+				return second.(int) - first.(int)
+			}).Intersect(right(), identity),
+			actual: []types.T{3, 2},
+		},
+		{
+			name: "unionAfterSortCase",
+			stream: left().Sorted(func(first types.T, second types.T) int {
+				return second.(int) - first.(int)
+			}).Union(right(), identity),
+			actual: []types.T{4, 3, 2, 1, 5},
+		},
+		{
+			name:   "emptyCase",
+			stream: OfElements().Union(OfElements(), identity),
+			actual: []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.actual, test.stream.ToSlice())
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package stream
+
+import (
+	"container/heap"
+	"github.com/chinalhr/go-stream/types"
+	"sort"
+	"sync"
+)
+
+//sortElements Sort the elements based on compare function and return the sorted elements.
+//If workers is greater than 1, the elements are split into shards by sourceSharding, every shard is sorted by
+//its own goroutine and the sorted shards are k-way merged. The merge takes equal elements from the earlier
+//shard first, so a stable sort stays stable.
+func sortElements(elements []types.T, compare func(e1 types.T, e2 types.T) int, stable bool, workers int) []types.T {
+	if workers <= 1 || len(elements) <= 1 {
+		sortShard(elements, compare, stable)
+		return elements
+	}
+
+	sharding := sourceSharding(len(elements), workers)
+	shards := make([]iterator, 0, len(sharding))
+	var wg sync.WaitGroup
+	wg.Add(len(sharding))
+	offset := 0
+	for _, s := range sharding {
+		shard := elements[offset : offset+s]
+		offset += s
+		shards = append(shards, buildSliceIterator(shard...))
+		go func() {
+			defer wg.Done()
+			sortShard(shard, compare, stable)
+		}()
+	}
+	wg.Wait()
+
+	merged := buildMergeIterator(compare, shards...)
+	result := make([]types.T, 0, len(elements))
+	for merged.HasNext() {
+		result = append(result, merged.Next())
+	}
+	return result
+}
+
+func sortShard(shard []types.T, compare func(e1 types.T, e2 types.T) int, stable bool) {
+	c := &Comparator{
+		source:  shard,
+		compare: compare,
+	}
+	if stable {
+		sort.Stable(c)
+	} else {
+		sort.Sort(c)
+	}
+}
+
+//mergeHead The current element of a merged source, source is the index of the source iterator.
+type mergeHead struct {
+	value  types.T
+	source int
+}
+
+//mergeIterator Lazily merges sorted iterators into a single sorted iterator through a heap of source heads.
+//Heads that compare equal are taken from the source with the lower index first.
+type mergeIterator struct {
+	multiIterator
+	heads       []mergeHead
+	compare     func(e1 types.T, e2 types.T) int
+	initialized bool
+}
+
+func (it *mergeIterator) Len() int {
+	return len(it.heads)
+}
+
+func (it *mergeIterator) Less(i, j int) bool {
+	if c := it.compare(it.heads[i].value, it.heads[j].value); c != 0 {
+		return c < 0
+	}
+	return it.heads[i].source < it.heads[j].source
+}
+
+func (it *mergeIterator) Swap(i, j int) {
+	it.heads[i], it.heads[j] = it.heads[j], it.heads[i]
+}
+
+func (it *mergeIterator) Push(x interface{}) {
+	it.heads = append(it.heads, x.(mergeHead))
+}
+
+func (it *mergeIterator) Pop() interface{} {
+	last := len(it.heads) - 1
+	head := it.heads[last]
+	it.heads = it.heads[:last]
+	return head
+}
+
+//init Read the first element of every source, sources are only read once the merge is consumed.
+func (it *mergeIterator) init() {
+	if it.initialized {
+		return
+	}
+	it.initialized = true
+	it.heads = make([]mergeHead, 0, len(it.sources))
+	for i, source := range it.sources {
+		if source.HasNext() {
+			it.heads = append(it.heads, mergeHead{value: source.Next(), source: i})
+		}
+	}
+	heap.Init(it)
+}
+
+//GetSize Returns the sum of the sizes of the sources, or -1 if the size of any source is unknown.
+func (it *mergeIterator) GetSize() int {
+	return it.sumSize()
+}
+
+func (it *mergeIterator) HasNext() bool {
+	it.init()
+	return len(it.heads) > 0
+}
+
+func (it *mergeIterator) Next() types.T {
+	it.init()
+	head := it.heads[0]
+	source := it.sources[head.source]
+	if source.HasNext() {
+		it.heads[0] = mergeHead{value: source.Next(), source: head.source}
+		heap.Fix(it, 0)
+	} else {
+		heap.Pop(it)
+	}
+	return head.value
+}
+
+func buildMergeIterator(compare func(e1 types.T, e2 types.T) int, sources ...iterator) *mergeIterator {
+	return &mergeIterator{
+		multiIterator: multiIterator{sources: sources},
+		compare:       compare,
+	}
+}
+
+//distinctSortedIterator Drops the elements of a sorted source that compare equal to the previous element.
+type distinctSortedIterator struct {
+	source  iterator
+	compare func(e1 types.T, e2 types.T) int
+	next    types.T
+	hasNext bool
+	last    types.T
+	started bool
+}
+
+func (it *distinctSortedIterator) GetSize() int {
+	return -1
+}
+
+func (it *distinctSortedIterator) HasNext() bool {
+	if it.hasNext {
+		return true
+	}
+	for it.source.HasNext() {
+		e := it.source.Next()
+		if it.started && it.compare(it.last, e) == 0 {
+			continue
+		}
+		it.next, it.hasNext = e, true
+		it.last, it.started = e, true
+		return true
+	}
+	return false
+}
+
+func (it *distinctSortedIterator) Next() types.T {
+	it.HasNext()
+	e := it.next
+	it.next, it.hasNext = nil, false
+	return e
+}
+
+func (it *distinctSortedIterator) Err() error {
+	if source, ok := it.source.(errIterator); ok {
+		return source.Err()
+	}
+	return nil
+}
+
+func (it *distinctSortedIterator) Close() error {
+	if source, ok := it.source.(closableIterator); ok {
+		return source.Close()
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package stream
+
+import (
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSortElements(t *testing.T) {
+	type pair struct {
+		key   int
+		value string
+	}
+	compare := func(e1 types.T, e2 types.T) int {
+		return e1.(pair).key - e2.(pair).key
+	}
+	input := func() []types.T {
+		return []types.T{pair{3, "a"}, pair{1, "b"}, pair{2, "c"}, pair{1, "d"}, pair{3, "e"}, pair{2, "f"}, pair{1, "g"}}
+	}
+	actual := []types.T{pair{1, "b"}, pair{1, "d"}, pair{1, "g"}, pair{2, "c"}, pair{2, "f"}, pair{3, "a"}, pair{3, "e"}}
+
+	tests := []struct {
+		name    string
+		workers int
+	}{
+		{
+			name:    "sequentialCase",
+			workers: 0,
+		},
+		{
+			name:    "twoWorkersCase",
+			workers: 2,
+		},
+		{
+			name:    "moreWorkersThanElementsCase",
+			workers: 16,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := sortElements(input(), compare, true, test.workers)
+			assert.Equal(t, actual, result)
+		})
+	}
+}
+
+func TestMergeIterator(t *testing.T) {
+	compare := func(e1 types.T, e2 types.T) int {
+		return e1.(int) - e2.(int)
+	}
+	testCases := []func(it iterator){
+		func(it iterator) {
+			size := it.GetSize()
+			assert.Equal(t, 7, size)
+		},
+		func(it iterator) {
+			result := make([]types.T, 0, 7)
+			for it.HasNext() {
+				result = append(result, it.Next())
+			}
+			assert.Equal(t, []types.T{1, 2, 3, 4, 5, 6, 7}, result)
+		},
+		func(it iterator) {
+			hasNext := it.HasNext()
+			assert.Equal(t, false, hasNext)
+		},
+	}
+
+	mergeIterator := buildMergeIterator(compare,
+		buildSliceIterator(1, 4, 7),
+		buildSliceIterator(),
+		buildSliceIterator(2, 3, 5, 6))
+	for _, testCase := range testCases {
+		t.Run("testCase", func(t *testing.T) {
+			testCase(mergeIterator)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package stream
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/chinalhr/go-stream/types"
+	"os"
+	"path/filepath"
+)
+
+//maxMergeFanIn The maximum number of runs merged at once, which bounds the number of open run files.
+const maxMergeFanIn = 64
+
+//externalSorter Sorts elements with bounded memory. Elements are buffered until memLimit is reached, then the
+//buffer is sorted and spilled to a temp file as a run. The runs are merged lazily by a mergeIterator, at most
+//fanIn at once, more runs are first merged into fewer runs by intermediate passes. spilled counts the run files
+//created, which names them.
+type externalSorter struct {
+	compare  func(e1 types.T, e2 types.T) int
+	codec    Codec
+	memLimit int
+	workers  int
+	fanIn    int
+	buffer   []types.T
+	dir      string
+	runs     []*spillRun
+	spilled  int
+}
+
+//spillRun A sorted run spilled to path, size is the number of elements of the run.
+type spillRun struct {
+	path string
+	size int
+	file *os.File
+	it   *decoderIterator
+}
+
+func newExternalSorter(compare func(e1 types.T, e2 types.T) int, codec Codec, memLimit int, workers int) *externalSorter {
+	return &externalSorter{
+		compare:  compare,
+		codec:    codec,
+		memLimit: memLimit,
+		workers:  workers,
+		fanIn:    maxMergeFanIn,
+		buffer:   make([]types.T, 0, memLimit),
+	}
+}
+
+//add Buffer e, the buffer is spilled once it holds memLimit elements.
+func (s *externalSorter) add(e types.T) error {
+	s.buffer = append(s.buffer, e)
+	if len(s.buffer) < s.memLimit {
+		return nil
+	}
+	return s.spill()
+}
+
+//spill Sort the buffer and write it to a new run file.
+func (s *externalSorter) spill() error {
+	run, err := s.writeRun(len(s.buffer), buildSliceIterator(sortElements(s.buffer, s.compare, true, s.workers)...))
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, run)
+	s.buffer = make([]types.T, 0, s.memLimit)
+	return nil
+}
+
+//writeRun Write the size elements of it to a new run file.
+func (s *externalSorter) writeRun(size int, it iterator) (*spillRun, error) {
+	if s.dir == "" {
+		dir, err := os.MkdirTemp("", "go-stream-sort-")
+		if err != nil {
+			return nil, err
+		}
+		s.dir = dir
+	}
+
+	path := filepath.Join(s.dir, fmt.Sprintf("run-%d", s.spilled))
+	s.spilled++
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	run := &spillRun{path: path, size: size}
+
+	w := bufio.NewWriter(file)
+	enc := s.codec.NewEncoder(w)
+	for it.HasNext() {
+		if err := enc.Encode(it.Next()); err != nil {
+			file.Close()
+			return run, err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return run, err
+	}
+	return run, file.Close()
+}
+
+//open Open the run file and return an iterator over its elements.
+func (s *externalSorter) open(run *spillRun) (iterator, error) {
+	file, err := os.Open(run.path)
+	if err != nil {
+		return nil, err
+	}
+	run.file = file
+	run.it = buildDecoderIterator(s.codec.NewDecoder(bufio.NewReader(file)), run.size)
+	return run.it, nil
+}
+
+//mergeRuns Merge consecutive groups of fanIn runs into single runs until fewer than fanIn runs are left, so the
+//final merge of the runs and the buffer opens at most fanIn sources. Merging consecutive runs keeps the runs in
+//encounter order.
+func (s *externalSorter) mergeRuns() error {
+	for len(s.runs) >= s.fanIn {
+		merged := make([]*spillRun, 0, len(s.runs)/s.fanIn+1)
+		for start := 0; start < len(s.runs); start += s.fanIn {
+			group := s.runs[start:minInt(start+s.fanIn, len(s.runs))]
+			if len(group) == 1 {
+				merged = append(merged, group[0])
+				continue
+			}
+			run, err := s.mergeGroup(group)
+			if err != nil {
+				return err
+			}
+			merged = append(merged, run)
+		}
+		s.runs = merged
+	}
+	return nil
+}
+
+//mergeGroup Merge the runs of group into a new run, the files of the runs are closed and removed. The files left
+//by an error are removed with the temp directory by close.
+func (s *externalSorter) mergeGroup(group []*spillRun) (*spillRun, error) {
+	sources := make([]iterator, 0, len(group))
+	size := 0
+	var err error
+	for _, run := range group {
+		var it iterator
+		if it, err = s.open(run); err != nil {
+			break
+		}
+		sources = append(sources, it)
+		size += run.size
+	}
+	var merged *spillRun
+	if err == nil {
+		merged, err = s.writeRun(size, buildMergeIterator(s.compare, sources...))
+	}
+	for _, run := range group {
+		if err == nil && run.it != nil {
+			err = run.it.Err()
+		}
+		if run.file != nil {
+			run.file.Close()
+			run.file = nil
+		}
+		run.it = nil
+		os.Remove(run.path)
+	}
+	return merged, err
+}
+
+//iterator Returns an iterator over all added elements in sorted order.
+//The sorted in-memory buffer is merged after the runs, so elements that compare equal keep their encounter order.
+func (s *externalSorter) iterator() (iterator, error) {
+	buffer := sortElements(s.buffer, s.compare, true, s.workers)
+	s.buffer = nil
+	if len(s.runs) == 0 {
+		return buildSliceIterator(buffer...), nil
+	}
+	if err := s.mergeRuns(); err != nil {
+		return nil, err
+	}
+
+	sources := make([]iterator, 0, len(s.runs)+1)
+	for _, run := range s.runs {
+		it, err := s.open(run)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, it)
+	}
+	sources = append(sources, buildSliceIterator(buffer...))
+	return buildMergeIterator(s.compare, sources...), nil
+}
+
+//err Returns the first error that occurred while reading the runs.
+func (s *externalSorter) err() error {
+	for _, run := range s.runs {
+		if run.it != nil && run.it.Err() != nil {
+			return run.it.Err()
+		}
+	}
+	return nil
+}
+
+//close Close the run files and remove the temp directory.
+func (s *externalSorter) close() error {
+	for _, run := range s.runs {
+		if run.file != nil {
+			run.file.Close()
+		}
+	}
+	s.runs = nil
+	s.buffer = nil
+	if s.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(s.dir)
+}
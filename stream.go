@@ -3,15 +3,19 @@ package stream
 import (
 	"errors"
 	"github.com/chinalhr/go-stream/types"
+	"math"
 	"reflect"
 	"sort"
+	"sync"
 )
 
 //Stream is a sequence of elements supporting sequential and parallel aggregate operations.
 //Stream operations are combined into a stream pipeline, and computational processing is performed during terminal operation.
-//Stream consists of a source(OfElements OfSlice OfMap Generate), zero or more intermediate operations(Filter Map Peek FlatMap
-//Distinct Sorted Skip Limit TakeWhile DropWhile), and terminal operations(ForEach FindLast FindFirst Reduce ReduceFromIdentity
-//Count Max Min ToSlice ToMap GroupingBy AllMatch AnyMatch NoneMatch FindFirst).
+//Stream consists of a source(OfElements OfSlice OfMap Generate Range RangeClosed Iterate IterateWhile Unfold OfLines
+//OfScanner), zero or more intermediate operations(Filter Map Peek FlatMap Distinct Sorted SortedStable
+//SortedExternal TopK BottomK Skip Limit TakeWhile DropWhile GroupBy Scan MapWithState), and terminal
+//operations(ForEach FindLast FindFirst Reduce ReduceFromIdentity Count Max Min ToSlice ToMap GroupingBy AllMatch
+//AnyMatch NoneMatch FindFirst).
 //Stream has lazy evaluation and short-circuit evaluation.
 //Example See: _example/example.go
 type Stream struct {
@@ -61,6 +65,69 @@ func Generate(supplier func() types.T) Stream {
 	return Stream{pipeline}
 }
 
+//Range Return a sequential Stream of ints from start (inclusive) to end (exclusive) by step, step can be negative.
+//The size of the Stream is known, so it can be pre-allocated and sharded by Parallel, Range panics if the size
+//does not fit in an int.
+func Range(start int, end int, step int) Stream {
+	if step == 0 {
+		panic(errors.New("step must not be zero"))
+	}
+	pipeline := newPipeline(buildRangeIterator(start, rangeSize(start, end, step, false), step))
+	return Stream{pipeline}
+}
+
+//RangeClosed Return a sequential Stream of ints from start (inclusive) to end (inclusive) by step, step can be
+//negative. RangeClosed panics if the size of the Stream does not fit in an int.
+func RangeClosed(start int, end int, step int) Stream {
+	if step == 0 {
+		panic(errors.New("step must not be zero"))
+	}
+	pipeline := newPipeline(buildRangeIterator(start, rangeSize(start, end, step, true), step))
+	return Stream{pipeline}
+}
+
+//rangeSize Returns the number of ints from start by step up to end, end is included if closed. The size is
+//calculated in uint64 so that ranges spanning more than math.MaxInt do not overflow, it panics if the size does not
+//fit in an int.
+func rangeSize(start int, end int, step int, closed bool) int {
+	var distance, stride uint64
+	switch {
+	case step > 0 && (start < end || closed && start == end):
+		distance, stride = uint64(end)-uint64(start), uint64(step)
+	case step < 0 && (start > end || closed && start == end):
+		distance, stride = uint64(start)-uint64(end), -uint64(step)
+	default:
+		return 0
+	}
+	if !closed {
+		distance--
+	}
+	if distance/stride >= math.MaxInt {
+		panic(errors.New("range size overflows int"))
+	}
+	return int(distance/stride) + 1
+}
+
+//Iterate Return an infinite sequential Stream of seed, next(seed), next(next(seed)) and so on.
+func Iterate(seed types.T, next func(e types.T) types.T) Stream {
+	pipeline := newPipeline(buildIterateIterator(seed, next))
+	return Stream{pipeline}
+}
+
+//IterateWhile Return a sequential Stream of seed, next(seed), next(next(seed)) and so on, the Stream ends at the
+//first element for which hasNext returns false.
+func IterateWhile(seed types.T, hasNext func(e types.T) bool, next func(e types.T) types.T) Stream {
+	pipeline := newPipeline(buildIterateWhileIterator(seed, hasNext, next))
+	return Stream{pipeline}
+}
+
+//Unfold Return a sequential Stream produced from state, fn returns an element and the next state, the Stream ends
+//when fn returns false.
+func Unfold(state types.T, fn func(state types.T) (value types.T, nextState types.T, ok bool)) Stream {
+	pipeline := newPipeline(buildUnfoldIterator(state, fn))
+	return Stream{pipeline}
+}
+
 //IntermediateStage stateless operation
 
 //Filter Returns a Stream consisting of the elements of this stream that match the given predicate function.
@@ -76,6 +143,7 @@ func (s Stream) Filter(predicate func(e types.T) bool) Stream {
 				}
 			}))
 		})
+	pipeline.keepSorted()
 	return s
 }
 
@@ -101,6 +169,7 @@ func (s Stream) Peek(consumer func(e types.T)) Stream {
 			next.Accept(e)
 		}))
 	})
+	pipeline.keepSorted()
 	return s
 }
 
@@ -142,29 +211,121 @@ func (s Stream) Distinct(distinctFn func(item types.T) types.R) Stream {
 			next.End()
 		}))
 	})
+	pipeline.keepSorted()
 	return s
 }
 
 //Sorted Return to orderly Stream. sort elements in Stream based on compare function.
+//Sorted followed by Limit(k) is executed as BottomK(k), only k elements are held in memory.
+//If the Stream is Parallel, the elements are sorted by a parallel merge sort.
+//If the Stream is SORTED, such as a Stream created by MergeSorted, the order is verified in O(n) and the elements
+//are only sorted if they are out of order.
 func (s Stream) Sorted(compare func(first types.T, second types.T) int) Stream {
+	pipeline := s.p
+	pipeline.addOperation(sortedWrapStage(pipeline, compare, false))
+	pipeline.currentOpt.compare = compare
+	pipeline.currentOpt.sorted = true
+	return s
+}
+
+//SortedStable Return to orderly Stream, elements that compare equal keep their encounter order.
+//The thenCompare functions are used in turn to order elements that compare equal by the previous function.
+//If the Stream is Parallel, the operations before the sort run sequentially so that the elements reach it in
+//encounter order, the shards of the workers are sorted in parallel and k-way merged in shard order.
+func (s Stream) SortedStable(compare func(first types.T, second types.T) int,
+	thenCompare ...func(first types.T, second types.T) int) Stream {
+	compare = chainCompare(compare, thenCompare...)
+	pipeline := s.p
+	pipeline.addOperation(sortedWrapStage(pipeline, compare, true))
+	pipeline.currentOpt.compare = compare
+	pipeline.currentOpt.sorted = true
+	pipeline.currentOpt.ordered = true
+	return s
+}
+
+//SortedExternal Return to orderly Stream, sorted with at most memLimit elements held in memory.
+//Whenever memLimit elements are buffered, they are sorted and spilled to a temp file encoded by codec,
+//the spilled runs are merged lazily when the elements flow downstream, at most 64 runs at once so the number of
+//open files stays bounded, more runs are first merged into fewer runs. Elements that compare equal keep
+//their encounter order, so on a Parallel Stream the operations before the sort run sequentially. The temp files
+//are removed when the sort ends or is cancelled, an I/O or encoding error stops the Stream and is returned by Err.
+//A following Limit does not replace the sort with a heap, which would hold all the limited elements in memory.
+func (s Stream) SortedExternal(compare func(first types.T, second types.T) int, codec Codec, memLimit int) Stream {
+	if memLimit <= 0 {
+		panic(errors.New("memLimit must be positive"))
+	}
 	pipeline := s.p
 	pipeline.addOperation(func(next stage) stage {
+		var sorter *externalSorter
+		var mutex sync.Mutex
+		return newDefaultIntermediateStage(next, beginFunc(func(size int) {
+			sorter = newExternalSorter(compare, codec, memLimit, pipeline.workers)
+			next.Begin(size)
+		}), acceptFunc(func(e types.T) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err := sorter.add(e); err != nil {
+				pipeline.fail(err)
+			}
+		}), cancellationRequestedFunc(func() bool {
+			return pipeline.failed() || next.CancellationRequested()
+		}), endFunc(func() {
+			defer func() {
+				if err := sorter.close(); err != nil {
+					pipeline.fail(err)
+				}
+				sorter = nil
+			}()
+			if pipeline.failed() {
+				next.End()
+				return
+			}
+			it, err := sorter.iterator()
+			if err != nil {
+				pipeline.fail(err)
+				next.End()
+				return
+			}
+			for it.HasNext() && !next.CancellationRequested() {
+				next.Accept(it.Next())
+			}
+			if err := sorter.err(); err != nil {
+				pipeline.fail(err)
+			}
+			next.End()
+		}))
+	})
+	pipeline.currentOpt.sorted = true
+	pipeline.currentOpt.ordered = true
+	return s
+}
+
+//sortedWrapStage Returns the wrapStage of Sorted and SortedStable, the elements are buffered and sorted in End.
+func sortedWrapStage(pipeline *referencePipeline, compare func(first types.T, second types.T) int,
+	stable bool) func(stage) stage {
+	presorted := pipeline.currentOpt.sorted
+	return func(next stage) stage {
 		var sortedList []types.T
+		var mutex sync.Mutex
 		return newDefaultIntermediateStage(next, beginFunc(func(size int) {
-			if size == -1 {
+			if size != -1 {
 				sortedList = make([]types.T, 0, size)
 			} else {
 				sortedList = make([]types.T, 0)
 			}
 			next.Begin(size)
 		}), acceptFunc(func(e types.T) {
+			mutex.Lock()
+			defer mutex.Unlock()
 			sortedList = append(sortedList, e)
 		}), endFunc(func() {
 			c := &Comparator{
 				source:  sortedList,
 				compare: compare,
 			}
-			sort.Sort(c)
+			if !presorted || !sort.IsSorted(c) {
+				sortedList = sortElements(sortedList, compare, stable, pipeline.workers)
+			}
 			next.Begin(len(sortedList))
 			for i := 0; i < len(sortedList) && !next.CancellationRequested(); i++ {
 				next.Accept(sortedList[i])
@@ -172,10 +333,52 @@ func (s Stream) Sorted(compare func(first types.T, second types.T) int) Stream {
 			next.End()
 			sortedList = nil
 		}))
+	}
+}
+
+//TopK Returns a Stream consisting of the k largest elements according to the compare function, in descending order.
+//Only k elements are held in memory, elements that compare equal keep their encounter order.
+func (s Stream) TopK(k int, compare func(first types.T, second types.T) int) Stream {
+	return s.BottomK(k, func(first types.T, second types.T) int {
+		return compare(second, first)
 	})
+}
+
+//BottomK Returns a Stream consisting of the k smallest elements according to the compare function, in ascending order.
+//Only k elements are held in memory, elements that compare equal keep their encounter order.
+func (s Stream) BottomK(k int, compare func(first types.T, second types.T) int) Stream {
+	pipeline := s.p
+	pipeline.addOperation(bottomKWrapStage(k, compare))
+	pipeline.currentOpt.sorted = true
 	return s
 }
 
+//bottomKWrapStage Returns the wrapStage of BottomK, the elements are retained by a boundedHeap and emitted in End.
+func bottomKWrapStage(k int, compare func(first types.T, second types.T) int) func(stage) stage {
+	return func(next stage) stage {
+		var h *boundedHeap
+		var mutex sync.Mutex
+		return newDefaultIntermediateStage(next, beginFunc(func(size int) {
+			h = newBoundedHeap(k, compare)
+			if size != -1 && size > h.limit {
+				size = h.limit
+			}
+			next.Begin(size)
+		}), acceptFunc(func(e types.T) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			h.offer(e)
+		}), endFunc(func() {
+			sortedList := h.sorted()
+			for i := 0; i < len(sortedList) && !next.CancellationRequested(); i++ {
+				next.Accept(sortedList[i])
+			}
+			next.End()
+			h = nil
+		}))
+	}
+}
+
 //Skip Discard the previous n elements, return the Stream of the remaining elements.
 func (s Stream) Skip(n int) Stream {
 	pipeline := s.p
@@ -202,12 +405,18 @@ func (s Stream) Skip(n int) Stream {
 			}
 		}))
 	})
+	pipeline.keepSorted()
 	return s
 }
 
 //Limit Returns a stream consisting of the elements of this Stream, truncated to be no longer than maxSize in length.
 func (s Stream) Limit(maxSize int) Stream {
 	pipeline := s.p
+	if compare := pipeline.currentOpt.compare; compare != nil {
+		pipeline.currentOpt.wrapStage = bottomKWrapStage(maxSize, compare)
+		pipeline.currentOpt.compare = nil
+		return s
+	}
 	pipeline.addOperation(func(next stage) stage {
 		var totalLimit = 0
 		if maxSize < 0 {
@@ -226,9 +435,10 @@ func (s Stream) Limit(maxSize int) Stream {
 			}
 			totalLimit++
 		}), cancellationRequestedFunc(func() bool {
-			return totalLimit == maxSize
+			return totalLimit >= maxSize || next.CancellationRequested()
 		}))
 	})
+	pipeline.keepSorted()
 	return s
 }
 
@@ -251,6 +461,7 @@ func (s Stream) TakeWhile(predicate func(t types.T) bool) Stream {
 			return !take || next.CancellationRequested()
 		}))
 	})
+	pipeline.keepSorted()
 	return s
 }
 
@@ -272,6 +483,37 @@ func (s Stream) DropWhile(predicate func(t types.T) bool) Stream {
 			}
 		}))
 	})
+	pipeline.keepSorted()
+	return s
+}
+
+//Scan Returns a Stream of the running accumulation of elements, the accumulator function is applied to the
+//state, starting from identity, and each element, and every new state is emitted.
+//Scan needs the elements in encounter order, so a Parallel Stream is evaluated sequentially.
+func (s Stream) Scan(identity types.T, accumulator func(state types.T, e types.T) types.T) Stream {
+	return s.MapWithState(identity, func(state types.T, e types.T) (types.T, types.R) {
+		newState := accumulator(state, e)
+		return newState, newState
+	})
+}
+
+//MapWithState Returns a Stream of elements transformed by the fn function, which receives the state, starting
+//from initialState, and each element, and returns the new state and the transformed element.
+//MapWithState needs the elements in encounter order, so a Parallel Stream is evaluated sequentially.
+func (s Stream) MapWithState(initialState types.T, fn func(state types.T, e types.T) (newState types.T, r types.R)) Stream {
+	pipeline := s.p
+	pipeline.addOperation(func(next stage) stage {
+		var state types.T
+		return newDefaultIntermediateStage(next, beginFunc(func(size int) {
+			state = initialState
+			next.Begin(size)
+		}), acceptFunc(func(e types.T) {
+			var r types.R
+			state, r = fn(state, e)
+			next.Accept(r)
+		}))
+	})
+	pipeline.currentOpt.ordered = true
 	return s
 }
 
@@ -337,9 +579,16 @@ func (s Stream) ReduceFromIdentity(identity types.T, accumulator func(e1 types.T
 
 //Count Returns the count of elements in this Stream.
 func (s Stream) Count() int {
-	return s.ReduceFromIdentity(0, func(e1 types.T, e2 types.T) types.T {
-		return e1.(int) + 1
-	}).(int)
+	return *collectCombining(s.p,
+		func() types.T {
+			return new(int)
+		},
+		func(acc types.T, e types.T) {
+			*acc.(*int)++
+		},
+		func(acc types.T, other types.T) {
+			*acc.(*int) += *other.(*int)
+		}).(*int)
 }
 
 //Max Compare through the compare function, return the max value in Stream.
@@ -495,6 +744,12 @@ func (s Stream) FindFirst() types.T {
 	return result
 }
 
+//Err Returns the first error that occurred while evaluating the Stream, such as an error of the source or
+//an I/O error of an operation that spills elements to disk. Err should be checked after the terminal operation.
+func (s Stream) Err() error {
+	return s.p.getErr()
+}
+
 //Parallel operation
 
 //Parallel Set the number of workers to perform Stream operations in parallel.
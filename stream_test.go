@@ -3,7 +3,11 @@ package stream
 import (
 	"github.com/chinalhr/go-stream/types"
 	"github.com/stretchr/testify/assert"
+	"io"
+	"math"
+	"os"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -155,6 +159,123 @@ func TestStream_Generate(t *testing.T) {
 	}
 }
 
+func TestStream_Range(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream Stream
+		size   int
+		actual []types.T
+	}{
+		{
+			name:   "rangeCase",
+			stream: Range(0, 10, 3),
+			size:   4,
+			actual: []types.T{0, 3, 6, 9},
+		},
+		{
+			name:   "negativeStepCase",
+			stream: Range(5, 0, -2),
+			size:   3,
+			actual: []types.T{5, 3, 1},
+		},
+		{
+			name:   "rangeClosedCase",
+			stream: RangeClosed(0, 9, 3),
+			size:   4,
+			actual: []types.T{0, 3, 6, 9},
+		},
+		{
+			name:   "rangeClosedNegativeStepCase",
+			stream: RangeClosed(4, 0, -2),
+			size:   3,
+			actual: []types.T{4, 2, 0},
+		},
+		{
+			name:   "emptyCase",
+			stream: Range(3, 3, 1),
+			size:   0,
+			actual: []types.T{},
+		},
+		{
+			name:   "wrongDirectionCase",
+			stream: RangeClosed(3, 0, 1),
+			size:   0,
+			actual: []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.size, test.stream.p.it.GetSize())
+			assert.Equal(t, test.actual, test.stream.ToSlice())
+		})
+	}
+
+	assert.Panics(t, func() {
+		Range(0, 1, 0)
+	})
+	assert.Equal(t, 0, Range(0, math.MaxInt, 2).FindFirst())
+	assert.Equal(t, math.MinInt, RangeClosed(math.MinInt, 0, 2).FindFirst())
+	assert.Equal(t, []types.T{math.MaxInt - 1, math.MaxInt}, RangeClosed(math.MaxInt-1, math.MaxInt, 1).ToSlice())
+	assert.Equal(t, []types.T{math.MinInt + 1}, Range(math.MinInt+1, math.MinInt, -3).ToSlice())
+	assert.Panics(t, func() {
+		RangeClosed(math.MinInt, 0, 1)
+	})
+	assert.Panics(t, func() {
+		Range(math.MinInt, math.MaxInt, 1)
+	})
+	assert.Equal(t, 5050, RangeClosed(1, 100, 1).Parallel(4).MapToInt(func(e types.T) int {
+		return e.(int)
+	}).Sum())
+}
+
+func TestStream_Iterate(t *testing.T) {
+	double := func(e types.T) types.T {
+		return e.(int) * 2
+	}
+
+	tests := []struct {
+		name   string
+		stream Stream
+		actual []types.T
+	}{
+		{
+			name:   "iterateCase",
+			stream: Iterate(1, double).Limit(5),
+			actual: []types.T{1, 2, 4, 8, 16},
+		},
+		{
+			name: "iterateWhileCase",
+			stream: IterateWhile(1, func(e types.T) bool {
+				return e.(int) < 20
+			}, double),
+			actual: []types.T{1, 2, 4, 8, 16},
+		},
+		{
+			name: "iterateWhileEmptyCase",
+			stream: IterateWhile(1, func(e types.T) bool {
+				return false
+			}, double),
+			actual: []types.T{},
+		},
+		{
+			name: "unfoldCase",
+			stream: Unfold([2]int{0, 1}, func(state types.T) (types.T, types.T, bool) {
+				fib := state.([2]int)
+				return fib[0], [2]int{fib[1], fib[0] + fib[1]}, fib[0] < 10
+			}),
+			actual: []types.T{0, 1, 1, 2, 3, 5, 8},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, -1, test.stream.p.it.GetSize())
+			assert.Equal(t, test.actual, test.stream.ToSlice())
+		})
+	}
+}
+
 //intermediateStage stateless operation test
 
 func TestStream_Filter(t *testing.T) {
@@ -405,6 +526,332 @@ func TestStream_Sorted(t *testing.T) {
 	}
 }
 
+func TestStream_SortedStable(t *testing.T) {
+	type widget struct {
+		color  string
+		weight int
+	}
+	byColor := func(first types.T, second types.T) int {
+		return strings.Compare(first.(widget).color, second.(widget).color)
+	}
+	byWeight := func(first types.T, second types.T) int {
+		return first.(widget).weight - second.(widget).weight
+	}
+	input := []types.T{widget{"red", 3}, widget{"blue", 2}, widget{"red", 1}, widget{"blue", 2}, widget{"red", 2}}
+
+	tests := []struct {
+		name        string
+		compare     func(first types.T, second types.T) int
+		thenCompare []func(first types.T, second types.T) int
+		workers     int
+		input       []types.T
+		actual      []types.T
+	}{
+		{
+			name:    "stableCase",
+			compare: byColor,
+			input:   input,
+			actual:  []types.T{widget{"blue", 2}, widget{"blue", 2}, widget{"red", 3}, widget{"red", 1}, widget{"red", 2}},
+		},
+		{
+			name:        "multiKeyCase",
+			compare:     byColor,
+			thenCompare: []func(first types.T, second types.T) int{byWeight},
+			input:       input,
+			actual:      []types.T{widget{"blue", 2}, widget{"blue", 2}, widget{"red", 1}, widget{"red", 2}, widget{"red", 3}},
+		},
+		{
+			name:        "parallelCase",
+			compare:     byColor,
+			thenCompare: []func(first types.T, second types.T) int{byWeight},
+			workers:     3,
+			input:       input,
+			actual:      []types.T{widget{"blue", 2}, widget{"blue", 2}, widget{"red", 1}, widget{"red", 2}, widget{"red", 3}},
+		},
+		{
+			name:    "nilCase",
+			compare: nil,
+			input:   nil,
+			actual:  []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := OfSlice(test.input).
+				Parallel(test.workers).
+				SortedStable(test.compare, test.thenCompare...).
+				ToSlice()
+			assert.Equal(t, result, test.actual)
+		})
+	}
+}
+
+func TestStream_SortedStableParallel(t *testing.T) {
+	input := make([]types.T, 2000)
+	for i := range input {
+		input[i] = types.KV{KEY: i % 7, VALUE: i}
+	}
+	byKey := func(first types.T, second types.T) int {
+		return first.(types.KV).KEY.(int) - second.(types.KV).KEY.(int)
+	}
+
+	for _, workers := range []int{2, 4, 7} {
+		t.Run(strconv.Itoa(workers), func(t *testing.T) {
+			result := OfSlice(input).
+				Parallel(workers).
+				Map(func(e types.T) types.R {
+					return e
+				}).
+				SortedStable(byKey).
+				ToSlice()
+			assert.Equal(t, len(input), len(result))
+			for i := 1; i < len(result); i++ {
+				prev, cur := result[i-1].(types.KV), result[i].(types.KV)
+				if prev.KEY == cur.KEY {
+					assert.Less(t, prev.VALUE, cur.VALUE)
+				}
+			}
+		})
+	}
+}
+
+func TestStream_SortedExternal(t *testing.T) {
+	compare := func(first types.T, second types.T) int {
+		return first.(int) - second.(int)
+	}
+
+	tests := []struct {
+		name     string
+		memLimit int
+		codec    Codec
+		input    []types.T
+		actual   []types.T
+		hasErr   bool
+	}{
+		{
+			name:     "spillCase",
+			memLimit: 2,
+			codec:    GobCodec(),
+			input:    []types.T{3, 2, 5, 1, 4},
+			actual:   []types.T{1, 2, 3, 4, 5},
+		},
+		{
+			name:     "inMemoryCase",
+			memLimit: 10,
+			codec:    GobCodec(),
+			input:    []types.T{3, 2, 5, 1, 4},
+			actual:   []types.T{1, 2, 3, 4, 5},
+		},
+		{
+			name:     "encodeErrCase",
+			memLimit: 1,
+			codec:    JSONCodec(nil),
+			input:    []types.T{func() {}},
+			actual:   []types.T{},
+			hasErr:   true,
+		},
+		{
+			name:     "nilCase",
+			memLimit: 1,
+			codec:    GobCodec(),
+			input:    nil,
+			actual:   []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("TMPDIR", dir)
+			s := OfSlice(test.input).
+				SortedExternal(compare, test.codec, test.memLimit)
+			result := s.ToSlice()
+			assert.Equal(t, result, test.actual)
+			assert.Equal(t, test.hasErr, s.Err() != nil)
+			entries, err := os.ReadDir(dir)
+			assert.Nil(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
+
+//countingCodec Counts the elements encoded by Codec.
+type countingCodec struct {
+	Codec
+	encoded int
+}
+
+func (c *countingCodec) NewEncoder(w io.Writer) Encoder {
+	enc := c.Codec.NewEncoder(w)
+	return encoderFunc(func(e types.T) error {
+		c.encoded++
+		return enc.Encode(e)
+	})
+}
+
+type encoderFunc func(e types.T) error
+
+func (f encoderFunc) Encode(e types.T) error {
+	return f(e)
+}
+
+func TestStream_SortedExternalLimit(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	codec := &countingCodec{Codec: GobCodec()}
+	result := OfElements(3, 2, 5, 1, 4).
+		SortedExternal(func(first types.T, second types.T) int {
+			return first.(int) - second.(int)
+		}, codec, 2).
+		Limit(3).
+		ToSlice()
+	assert.Equal(t, []types.T{1, 2, 3}, result)
+	assert.Less(t, 0, codec.encoded)
+}
+
+func TestExternalSorter_FanIn(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	byKey := func(first types.T, second types.T) int {
+		return first.(types.KV).KEY.(int) - second.(types.KV).KEY.(int)
+	}
+	sorter := newExternalSorter(byKey, GobCodec(), 2, 1)
+	sorter.fanIn = 3
+	for i := 0; i < 41; i++ {
+		assert.NoError(t, sorter.add(types.KV{KEY: (i * 7) % 5, VALUE: i}))
+	}
+	assert.Equal(t, 20, len(sorter.runs))
+
+	it, err := sorter.iterator()
+	assert.NoError(t, err)
+	assert.Less(t, len(sorter.runs), sorter.fanIn)
+	var result []types.T
+	for it.HasNext() {
+		result = append(result, it.Next())
+	}
+	assert.NoError(t, sorter.err())
+	assert.Equal(t, 41, len(result))
+	for i := 1; i < len(result); i++ {
+		prev, cur := result[i-1].(types.KV), result[i].(types.KV)
+		assert.LessOrEqual(t, prev.KEY, cur.KEY)
+		if prev.KEY == cur.KEY {
+			assert.Less(t, prev.VALUE, cur.VALUE)
+		}
+	}
+
+	assert.NoError(t, sorter.close())
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestStream_TopK(t *testing.T) {
+	tests := []struct {
+		name    string
+		k       int
+		compare func(first types.T, second types.T) int
+		input   []types.T
+		actual  []types.T
+	}{
+		{
+			name: "normalCase",
+			k:    3,
+			compare: func(first types.T, second types.T) int {
+				return first.(int) - second.(int)
+			},
+			input:  []types.T{3, 2, 5, 1, 4},
+			actual: []types.T{5, 4, 3},
+		},
+		{
+			name: "emptyCase",
+			k:    3,
+			compare: func(first types.T, second types.T) int {
+				return first.(int) - second.(int)
+			},
+			input:  []types.T{},
+			actual: []types.T{},
+		},
+		{
+			name:    "nilCase",
+			k:       0,
+			compare: nil,
+			input:   nil,
+			actual:  []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := OfSlice(test.input).
+				TopK(test.k, test.compare).
+				ToSlice()
+			assert.Equal(t, result, test.actual)
+		})
+	}
+}
+
+func TestStream_BottomK(t *testing.T) {
+	tests := []struct {
+		name    string
+		k       int
+		compare func(first types.T, second types.T) int
+		input   []types.T
+		actual  []types.T
+	}{
+		{
+			name: "normalCase",
+			k:    3,
+			compare: func(first types.T, second types.T) int {
+				return first.(int) - second.(int)
+			},
+			input:  []types.T{3, 2, 5, 1, 4},
+			actual: []types.T{1, 2, 3},
+		},
+		{
+			name: "greaterThanSizeCase",
+			k:    10,
+			compare: func(first types.T, second types.T) int {
+				return first.(int) - second.(int)
+			},
+			input:  []types.T{3, 2, 5, 1, 4},
+			actual: []types.T{1, 2, 3, 4, 5},
+		},
+		{
+			name:    "nilCase",
+			k:       0,
+			compare: nil,
+			input:   nil,
+			actual:  []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := OfSlice(test.input).
+				BottomK(test.k, test.compare).
+				ToSlice()
+			assert.Equal(t, result, test.actual)
+		})
+	}
+}
+
+func TestStream_SortedLimit(t *testing.T) {
+	compare := func(first types.T, second types.T) int {
+		return first.(int) - second.(int)
+	}
+
+	s := OfSlice([]types.T{3, 2, 5, 1, 4}).
+		Filter(func(e types.T) bool {
+			return e.(int) != 2
+		}).
+		Sorted(compare).
+		Limit(2)
+	fused := s.p.currentOpt
+	assert.Nil(t, fused.compare)
+	assert.Nil(t, fused.preOpt.compare)
+	assert.Equal(t, []types.T{1, 3}, s.ToSlice())
+}
+
 func TestStream_Skip(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -563,6 +1010,85 @@ func TestStream_DropWhile(t *testing.T) {
 
 //terminal non-short-circuiting operation
 
+func TestStream_Scan(t *testing.T) {
+	tests := []struct {
+		name        string
+		identity    types.T
+		accumulator func(state types.T, e types.T) types.T
+		input       []types.T
+		actual      []types.T
+	}{
+		{
+			name:     "runningTotalCase",
+			identity: 0,
+			accumulator: func(state types.T, e types.T) types.T {
+				return state.(int) + e.(int)
+			},
+			input:  []types.T{1, 2, 3, 4, 5},
+			actual: []types.T{1, 3, 6, 10, 15},
+		},
+		{
+			name:     "runningMaxCase",
+			identity: 0,
+			accumulator: func(state types.T, e types.T) types.T {
+				if e.(int) > state.(int) {
+					return e
+				}
+				return state
+			},
+			input:  []types.T{3, 1, 4, 1, 5},
+			actual: []types.T{3, 3, 4, 4, 5},
+		},
+		{
+			name:        "nilCase",
+			identity:    0,
+			accumulator: nil,
+			input:       nil,
+			actual:      []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := OfSlice(test.input).
+				Scan(test.identity, test.accumulator).
+				ToSlice()
+			assert.Equal(t, result, test.actual)
+		})
+	}
+}
+
+func TestStream_MapWithState(t *testing.T) {
+	//emit the difference between each element and the previous one.
+	result := OfElements(1, 4, 9, 16).
+		MapWithState(0, func(state types.T, e types.T) (types.T, types.R) {
+			return e, e.(int) - state.(int)
+		}).
+		ToSlice()
+	assert.Equal(t, []types.T{1, 3, 5, 7}, result)
+
+	total := Range(0, 100, 1).
+		Parallel(4).
+		Scan(0, func(state types.T, e types.T) types.T {
+			return state.(int) + e.(int)
+		}).
+		Max(func(first types.T, second types.T) int {
+			return first.(int) - second.(int)
+		})
+	assert.Equal(t, 4950, total)
+
+	totals := Range(0, 2000, 1).
+		Parallel(8).
+		Scan(0, func(state types.T, e types.T) types.T {
+			return state.(int) + e.(int)
+		}).
+		ToSlice()
+	assert.Equal(t, 2000, len(totals))
+	for i, total := range totals {
+		assert.Equal(t, i*(i+1)/2, total)
+	}
+}
+
 func TestStream_ForEach(t *testing.T) {
 
 	tests := []struct {
@@ -0,0 +1,268 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/chinalhr/go-stream/types"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+//TailLine Is a line of a file followed by OfTail, Offset is the byte offset in the file just after the line,
+//which can be saved to resume following the file by FromOffset.
+type TailLine struct {
+	Path   string
+	Text   string
+	Offset int64
+}
+
+//TailOption Configures OfTail.
+type TailOption func(o *tailOptions)
+
+//tailOptions
+//offset is the offset to start reading the file from, -1 means the end of the file.
+//pollInterval is the interval of checking the file for new lines, truncation and rotation.
+//ctx ends the Stream when it is done.
+type tailOptions struct {
+	offset       int64
+	pollInterval time.Duration
+	ctx          context.Context
+}
+
+//FromBeginning Returns a TailOption reading the file from its beginning.
+func FromBeginning() TailOption {
+	return func(o *tailOptions) {
+		o.offset = 0
+	}
+}
+
+//FromEnd Returns a TailOption reading only the lines appended to the file after the Stream starts, the default.
+func FromEnd() TailOption {
+	return func(o *tailOptions) {
+		o.offset = -1
+	}
+}
+
+//FromOffset Returns a TailOption reading the file from offset, such as the Offset of the last TailLine processed
+//before a restart. If the file is shorter than offset, it is read from its beginning.
+func FromOffset(offset int64) TailOption {
+	if offset < 0 {
+		panic(errors.New("offset must not be negative"))
+	}
+	return func(o *tailOptions) {
+		o.offset = offset
+	}
+}
+
+//WithPollInterval Returns a TailOption checking the file every interval, 250ms by default.
+func WithPollInterval(interval time.Duration) TailOption {
+	if interval <= 0 {
+		panic(errors.New("poll interval must be positive"))
+	}
+	return func(o *tailOptions) {
+		o.pollInterval = interval
+	}
+}
+
+//WithContext Returns a TailOption ending the Stream once ctx is done, without an error.
+func WithContext(ctx context.Context) TailOption {
+	return func(o *tailOptions) {
+		o.ctx = ctx
+	}
+}
+
+//OfTail Return a sequential Stream of the TailLine of the file at path, following the file like tail -F.
+//When the end of the file is reached, the file is polled for appended lines. A partial last line is held back
+//until it is completed. If the file shrinks below the offset read so far, it is considered truncated and read
+//again from its beginning. If path is replaced by another file, the rest of the current file is read and then
+//the new file from its beginning. A file that does not exist yet is waited for. The Stream is infinite unless
+//WithContext is given, or the evaluation is short-circuited, the file is closed once the Stream has been
+//evaluated. Other errors of reading the file end the Stream and are returned by Err.
+func OfTail(path string, opts ...TailOption) Stream {
+	o := &tailOptions{
+		offset:       -1,
+		pollInterval: 250 * time.Millisecond,
+		ctx:          context.Background(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	pipeline := newPipeline(&tailIterator{
+		path:    path,
+		options: o,
+	})
+	return Stream{pipeline}
+}
+
+//tailIterator A general type iterator following a file.
+//offset is the offset in the current file of the bytes read, partial is the incomplete last line read.
+//started is set once the file has been looked for, the start option only applies to a file found at the start.
+type tailIterator struct {
+	iteratorInfiniteBaseInfo
+	path    string
+	options *tailOptions
+	file    *os.File
+	info    os.FileInfo
+	reader  *bufio.Reader
+	offset  int64
+	partial string
+	started bool
+	next    TailLine
+	hasNext bool
+	done    bool
+	err     error
+}
+
+func (it *tailIterator) HasNext() bool {
+	if it.hasNext {
+		return true
+	}
+	for !it.done {
+		if it.options.ctx.Err() != nil {
+			it.done = true
+			return false
+		}
+		if it.file == nil {
+			if !it.open() && !it.wait() {
+				return false
+			}
+			continue
+		}
+		line, err := it.reader.ReadString('\n')
+		it.offset += int64(len(line))
+		if err == nil {
+			it.emit(it.partial + line)
+			return true
+		}
+		if err != io.EOF {
+			it.fail(err)
+			return false
+		}
+		it.partial += line
+		if it.rotated() {
+			return true
+		}
+		if it.file != nil && !it.wait() {
+			return false
+		}
+	}
+	return false
+}
+
+func (it *tailIterator) emit(line string) {
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	it.next = TailLine{Path: it.path, Text: line, Offset: it.offset}
+	it.hasNext = true
+	it.partial = ""
+}
+
+//open Open the file at path, returns false if it does not exist yet. The first file opened is positioned by the
+//start option, the files opened after a rotation, or after waiting for the file to be created, are read from
+//their beginning.
+func (it *tailIterator) open() bool {
+	file, err := os.Open(it.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			it.fail(err)
+		}
+		it.started = true
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		it.fail(err)
+		return false
+	}
+	offset := int64(0)
+	if !it.started {
+		switch {
+		case it.options.offset == -1:
+			offset = info.Size()
+		case it.options.offset <= info.Size():
+			offset = it.options.offset
+		}
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		it.fail(err)
+		return false
+	}
+	it.file, it.info, it.offset = file, info, offset
+	it.reader = bufio.NewReader(file)
+	it.started = true
+	return true
+}
+
+//rotated Check the file at path once the current file is read to its end. A truncated file is read again from
+//its beginning, a replaced file is closed and the new file is opened by the next HasNext. Returns true if the
+//partial last line of a replaced file has been emitted.
+func (it *tailIterator) rotated() bool {
+	info, err := os.Stat(it.path)
+	if err != nil {
+		return false
+	}
+	if !os.SameFile(it.info, info) {
+		_ = it.file.Close()
+		it.file = nil
+		if it.partial != "" {
+			it.emit(it.partial)
+			return true
+		}
+		return false
+	}
+	if info.Size() < it.offset {
+		if _, err := it.file.Seek(0, io.SeekStart); err != nil {
+			it.fail(err)
+			return false
+		}
+		it.reader.Reset(it.file)
+		it.offset, it.partial = 0, ""
+	}
+	return false
+}
+
+//wait Wait for the poll interval, returns false if the context is done first.
+func (it *tailIterator) wait() bool {
+	if it.done {
+		return false
+	}
+	timer := time.NewTimer(it.options.pollInterval)
+	defer timer.Stop()
+	select {
+	case <-it.options.ctx.Done():
+		it.done = true
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (it *tailIterator) fail(err error) {
+	it.err, it.done = err, true
+}
+
+func (it *tailIterator) Next() types.T {
+	it.HasNext()
+	e := it.next
+	it.next, it.hasNext = TailLine{}, false
+	return e
+}
+
+func (it *tailIterator) Err() error {
+	return it.err
+}
+
+func (it *tailIterator) Close() error {
+	it.done = true
+	if it.file == nil {
+		return nil
+	}
+	err := it.file.Close()
+	it.file = nil
+	return err
+}
@@ -0,0 +1,135 @@
+package stream
+
+import (
+	"context"
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func appendFile(t *testing.T, path string, text string) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	assert.NoError(t, err)
+	_, err = file.WriteString(text)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+}
+
+//tailTexts Follow path until ctx is done, calling action with the texts read so far after every line.
+func tailTexts(ctx context.Context, path string, action func(texts []string), opts ...TailOption) (Stream, []string) {
+	texts := make([]string, 0)
+	s := OfTail(path, append([]TailOption{WithContext(ctx), WithPollInterval(time.Millisecond)}, opts...)...)
+	s.ForEach(func(e types.T) {
+		texts = append(texts, e.(TailLine).Text)
+		action(texts)
+	})
+	return s, texts
+}
+
+func TestOfTail(t *testing.T) {
+	t.Run("fromBeginningCase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "a\nb\r\nc")
+		actual := OfTail(path, FromBeginning()).Limit(2).ToSlice()
+		assert.Equal(t, []types.T{
+			TailLine{Path: path, Text: "a", Offset: 2},
+			TailLine{Path: path, Text: "b", Offset: 5},
+		}, actual)
+	})
+
+	t.Run("followCase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "old\n")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		appendFile(t, path, "new\npart")
+		s, texts := tailTexts(ctx, path, func(texts []string) {
+			switch len(texts) {
+			case 1:
+				appendFile(t, path, "ial\n")
+			case 2:
+				cancel()
+			}
+		}, FromOffset(4))
+		assert.Equal(t, []string{"new", "partial"}, texts)
+		assert.NoError(t, s.Err())
+	})
+
+	t.Run("fromEndCase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "old\n")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			appendFile(t, path, "new\n")
+		}()
+		_, texts := tailTexts(ctx, path, func(texts []string) {
+			cancel()
+		})
+		assert.Equal(t, []string{"new"}, texts)
+	})
+
+	t.Run("fromOffsetCase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "a\nb\nc\n")
+		line := OfTail(path, FromOffset(2)).FindFirst().(TailLine)
+		assert.Equal(t, "b", line.Text)
+		line = OfTail(path, FromOffset(line.Offset)).FindFirst().(TailLine)
+		assert.Equal(t, "c", line.Text)
+		line = OfTail(path, FromOffset(100)).FindFirst().(TailLine)
+		assert.Equal(t, "a", line.Text)
+	})
+
+	t.Run("truncateCase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "first line\nsecond line\n")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, texts := tailTexts(ctx, path, func(texts []string) {
+			switch len(texts) {
+			case 2:
+				assert.NoError(t, os.WriteFile(path, []byte("x\n"), 0o644))
+			case 3:
+				cancel()
+			}
+		}, FromBeginning())
+		assert.Equal(t, []string{"first line", "second line", "x"}, texts)
+	})
+
+	t.Run("rotateCase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "a\n")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, texts := tailTexts(ctx, path, func(texts []string) {
+			switch len(texts) {
+			case 1:
+				appendFile(t, path, "last")
+				assert.NoError(t, os.Rename(path, path+".1"))
+				appendFile(t, path, "n\n")
+			case 3:
+				cancel()
+			}
+		}, FromBeginning())
+		assert.Equal(t, []string{"a", "last", "n"}, texts)
+	})
+
+	t.Run("missingFileCase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			appendFile(t, path, "created\n")
+		}()
+		s, texts := tailTexts(ctx, path, func(texts []string) {
+			cancel()
+		})
+		assert.Equal(t, []string{"created"}, texts)
+		assert.NoError(t, s.Err())
+	})
+}
@@ -9,4 +9,24 @@ type (
 		KEY   T
 		VALUE T
 	}
+
+	//Group Is the Elements that have the same Key.
+	Group struct {
+		Key      K
+		Elements []T
+	}
+
+	//CoGroup Is the elements of two streams that have the same Key, Left from the first stream and Right
+	//from the second stream.
+	CoGroup struct {
+		Key   K
+		Left  []T
+		Right []T
+	}
+
+	//WindowValue Is an element enriched with the Value computed by a window function.
+	WindowValue struct {
+		Element T
+		Value   T
+	}
 )
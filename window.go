@@ -0,0 +1,150 @@
+package stream
+
+import (
+	"errors"
+	"github.com/chinalhr/go-stream/types"
+)
+
+//Windowing operation
+//The windowing operations emit []types.T batches of consecutive elements. They need the elements in encounter
+//order, so a Parallel Stream is evaluated sequentially.
+
+//Chunk Returns a Stream of []types.T batches of n consecutive elements, the last batch holds the remaining
+//elements and may be shorter than n.
+func (s Stream) Chunk(n int) Stream {
+	if n <= 0 {
+		panic(errors.New("chunk size must be positive"))
+	}
+	pipeline := s.p
+	pipeline.addOperation(func(next stage) stage {
+		var chunk []types.T
+		return newDefaultIntermediateStage(next, beginFunc(func(size int) {
+			chunk = make([]types.T, 0, n)
+			if size == -1 {
+				next.Begin(-1)
+				return
+			}
+			next.Begin((size + n - 1) / n)
+		}), acceptFunc(func(e types.T) {
+			chunk = append(chunk, e)
+			if len(chunk) == n {
+				next.Accept(chunk)
+				chunk = make([]types.T, 0, n)
+			}
+		}), endFunc(func() {
+			if len(chunk) > 0 && !next.CancellationRequested() {
+				next.Accept(chunk)
+			}
+			chunk = nil
+			next.End()
+		}))
+	})
+	pipeline.currentOpt.ordered = true
+	return s
+}
+
+//Sliding Returns a Stream of []types.T windows of size consecutive elements, every window starts step elements
+//after the previous one. Only full windows are emitted, so a Stream with less than size elements is empty.
+func (s Stream) Sliding(size int, step int) Stream {
+	if size <= 0 || step <= 0 {
+		panic(errors.New("window size and step must be positive"))
+	}
+	pipeline := s.p
+	pipeline.addOperation(func(next stage) stage {
+		var window []types.T
+		var skip int
+		return newDefaultIntermediateStage(next, beginFunc(func(sourceSize int) {
+			window = make([]types.T, 0, size)
+			skip = 0
+			switch {
+			case sourceSize == -1:
+				next.Begin(-1)
+			case sourceSize < size:
+				next.Begin(0)
+			default:
+				next.Begin((sourceSize-size)/step + 1)
+			}
+		}), acceptFunc(func(e types.T) {
+			if skip > 0 {
+				skip--
+				return
+			}
+			window = append(window, e)
+			if len(window) < size {
+				return
+			}
+			emit := make([]types.T, size)
+			copy(emit, window)
+			next.Accept(emit)
+			if step >= size {
+				skip = step - size
+				window = window[:0]
+			} else {
+				window = append(window[:0], window[step:]...)
+			}
+		}), endFunc(func() {
+			window = nil
+			next.End()
+		}))
+	})
+	pipeline.currentOpt.ordered = true
+	return s
+}
+
+//GroupAdjacent Returns a Stream of []types.T batches of consecutive elements that have the same key, the key is
+//calculated by the keyFn function and compared by ==.
+func (s Stream) GroupAdjacent(keyFn func(e types.T) types.K) Stream {
+	pipeline := s.p
+	var lastKey types.K
+	pipeline.addOperation(chunkWhileWrapStage(func() {
+		lastKey = nil
+	}, func(first bool, e types.T) bool {
+		key := keyFn(e)
+		same := !first && key == lastKey
+		lastKey = key
+		return same
+	}))
+	pipeline.currentOpt.ordered = true
+	return s
+}
+
+//ChunkWhile Returns a Stream of []types.T batches of consecutive elements, an element belongs to the batch of the
+//previous element as long as predicate(previous, element) returns true.
+func (s Stream) ChunkWhile(predicate func(previous types.T, e types.T) bool) Stream {
+	pipeline := s.p
+	var previous types.T
+	pipeline.addOperation(chunkWhileWrapStage(func() {
+		previous = nil
+	}, func(first bool, e types.T) bool {
+		same := !first && predicate(previous, e)
+		previous = e
+		return same
+	}))
+	pipeline.currentOpt.ordered = true
+	return s
+}
+
+//chunkWhileWrapStage Returns the wrapStage of GroupAdjacent and ChunkWhile. reset is called in Begin, sameChunk
+//returns whether e belongs to the current batch, first is true for the first element.
+func chunkWhileWrapStage(reset func(), sameChunk func(first bool, e types.T) bool) func(stage) stage {
+	return func(next stage) stage {
+		var chunk []types.T
+		return newDefaultIntermediateStage(next, beginFunc(func(size int) {
+			chunk = nil
+			reset()
+			next.Begin(-1)
+		}), acceptFunc(func(e types.T) {
+			if !sameChunk(chunk == nil, e) && chunk != nil {
+				next.Accept(chunk)
+				chunk = nil
+			}
+			chunk = append(chunk, e)
+		}), endFunc(func() {
+			if chunk != nil && !next.CancellationRequested() {
+				next.Accept(chunk)
+			}
+			chunk = nil
+			next.End()
+		}))
+	}
+}
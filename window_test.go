@@ -0,0 +1,139 @@
+package stream
+
+import (
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStream_Chunk(t *testing.T) {
+	tests := []struct {
+		name   string
+		n      int
+		input  []types.T
+		actual []types.T
+	}{
+		{
+			name:   "normalCase",
+			n:      2,
+			input:  []types.T{1, 2, 3, 4, 5},
+			actual: []types.T{[]types.T{1, 2}, []types.T{3, 4}, []types.T{5}},
+		},
+		{
+			name:   "exactCase",
+			n:      2,
+			input:  []types.T{1, 2, 3, 4},
+			actual: []types.T{[]types.T{1, 2}, []types.T{3, 4}},
+		},
+		{
+			name:   "nilCase",
+			n:      2,
+			input:  nil,
+			actual: []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := OfSlice(test.input).
+				Chunk(test.n).
+				ToSlice()
+			assert.Equal(t, test.actual, result)
+		})
+	}
+
+	assert.Equal(t, []types.T{[]types.T{1, 2}}, OfElements(1, 2, 3).Chunk(2).Limit(1).ToSlice())
+	assert.Panics(t, func() {
+		OfElements().Chunk(0)
+	})
+}
+
+func TestStream_Sliding(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		step   int
+		input  []types.T
+		actual []types.T
+	}{
+		{
+			name:   "overlapCase",
+			size:   3,
+			step:   1,
+			input:  []types.T{1, 2, 3, 4, 5},
+			actual: []types.T{[]types.T{1, 2, 3}, []types.T{2, 3, 4}, []types.T{3, 4, 5}},
+		},
+		{
+			name:   "gapCase",
+			size:   2,
+			step:   3,
+			input:  []types.T{1, 2, 3, 4, 5, 6, 7},
+			actual: []types.T{[]types.T{1, 2}, []types.T{4, 5}},
+		},
+		{
+			name:   "shortCase",
+			size:   3,
+			step:   1,
+			input:  []types.T{1, 2},
+			actual: []types.T{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := OfSlice(test.input).
+				Sliding(test.size, test.step).
+				ToSlice()
+			assert.Equal(t, test.actual, result)
+		})
+	}
+}
+
+func TestStream_GroupAdjacent(t *testing.T) {
+	result := OfElements("a1", "a2", "b1", "a3", "c1", "c2").
+		GroupAdjacent(func(e types.T) types.K {
+			return e.(string)[:1]
+		}).
+		ToSlice()
+	assert.Equal(t, []types.T{
+		[]types.T{"a1", "a2"}, []types.T{"b1"}, []types.T{"a3"}, []types.T{"c1", "c2"},
+	}, result)
+
+	assert.Equal(t, []types.T{}, OfElements().GroupAdjacent(func(e types.T) types.K {
+		return e
+	}).ToSlice())
+}
+
+func TestStream_ChunkWhile(t *testing.T) {
+	result := OfElements(1, 2, 3, 5, 6, 9).
+		ChunkWhile(func(previous types.T, e types.T) bool {
+			return e.(int) == previous.(int)+1
+		}).
+		ToSlice()
+	assert.Equal(t, []types.T{[]types.T{1, 2, 3}, []types.T{5, 6}, []types.T{9}}, result)
+}
+
+func TestStream_WindowParallel(t *testing.T) {
+	chunks := Range(0, 1000, 1).Parallel(8).Chunk(10).ToSlice()
+	assert.Equal(t, 100, len(chunks))
+	for i, chunk := range chunks {
+		assert.Equal(t, i*10, chunk.([]types.T)[0])
+		assert.Equal(t, i*10+9, chunk.([]types.T)[9])
+	}
+
+	windows := Range(0, 1000, 1).Parallel(8).Sliding(2, 1).ToSlice()
+	assert.Equal(t, 999, len(windows))
+	for i, window := range windows {
+		assert.Equal(t, []types.T{i, i + 1}, window)
+	}
+
+	groups := Range(0, 1000, 1).Parallel(8).GroupAdjacent(func(e types.T) types.K {
+		return e.(int) / 100
+	}).ToSlice()
+	assert.Equal(t, 10, len(groups))
+
+	runs := Range(0, 1000, 1).Parallel(8).ChunkWhile(func(previous types.T, e types.T) bool {
+		return e.(int) == previous.(int)+1
+	}).ToSlice()
+	assert.Equal(t, 1, len(runs))
+}
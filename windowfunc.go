@@ -0,0 +1,162 @@
+package stream
+
+import (
+	"github.com/chinalhr/go-stream/types"
+	"sync"
+)
+
+//WindowSpec Defines the partitions and the order of rows for window functions, created by Over.
+//A window function emits every element of the Stream as a types.WindowValue, enriched with the value computed
+//over the partition of the element. The elements are emitted partition by partition, in the order in which the
+//partitions first appear, and in the order of orderBy within a partition.
+type WindowSpec struct {
+	s           Stream
+	partitionBy func(e types.T) types.K
+	orderBy     func(first types.T, second types.T) int
+}
+
+//Over Returns a WindowSpec partitioning elements by the key calculated by partitionBy, and ordering the elements
+//of a partition stably by the orderBy function. A nil partitionBy puts all elements in one partition, a nil
+//orderBy keeps the encounter order. The window functions buffer all elements.
+func (s Stream) Over(partitionBy func(e types.T) types.K, orderBy func(first types.T, second types.T) int) WindowSpec {
+	return WindowSpec{
+		s:           s,
+		partitionBy: partitionBy,
+		orderBy:     orderBy,
+	}
+}
+
+//RowNumber Returns a Stream of types.WindowValue with the 1-based number of the row within its partition.
+func (w WindowSpec) RowNumber() Stream {
+	return w.apply(func(rows []types.T) []types.T {
+		values := make([]types.T, len(rows))
+		for i := range rows {
+			values[i] = i + 1
+		}
+		return values
+	})
+}
+
+//Rank Returns a Stream of types.WindowValue with the rank of the row within its partition, rows that compare
+//equal by orderBy have the same rank, and leave a gap in the following ranks.
+func (w WindowSpec) Rank() Stream {
+	return w.apply(func(rows []types.T) []types.T {
+		values := make([]types.T, len(rows))
+		for i := range rows {
+			if i > 0 && w.peers(rows[i-1], rows[i]) {
+				values[i] = values[i-1]
+			} else {
+				values[i] = i + 1
+			}
+		}
+		return values
+	})
+}
+
+//DenseRank Returns a Stream of types.WindowValue with the rank of the row within its partition, rows that compare
+//equal by orderBy have the same rank, without gaps in the following ranks.
+func (w WindowSpec) DenseRank() Stream {
+	return w.apply(func(rows []types.T) []types.T {
+		values := make([]types.T, len(rows))
+		rank := 0
+		for i := range rows {
+			if i == 0 || !w.peers(rows[i-1], rows[i]) {
+				rank++
+			}
+			values[i] = rank
+		}
+		return values
+	})
+}
+
+//Lag Returns a Stream of types.WindowValue with the element n rows before the row within its partition,
+//or nil if there is no such row.
+func (w WindowSpec) Lag(n int) Stream {
+	return w.apply(func(rows []types.T) []types.T {
+		return shiftRows(rows, -n)
+	})
+}
+
+//Lead Returns a Stream of types.WindowValue with the element n rows after the row within its partition,
+//or nil if there is no such row.
+func (w WindowSpec) Lead(n int) Stream {
+	return w.apply(func(rows []types.T) []types.T {
+		return shiftRows(rows, n)
+	})
+}
+
+//MovingAvg Returns a Stream of types.WindowValue with the float64 average of valueFn over the row and up to n-1
+//rows before it within its partition.
+func (w WindowSpec) MovingAvg(n int, valueFn func(e types.T) float64) Stream {
+	if n < 1 {
+		n = 1
+	}
+	return w.apply(func(rows []types.T) []types.T {
+		values := make([]types.T, len(rows))
+		sum := 0.0
+		for i, row := range rows {
+			sum += valueFn(row)
+			if i >= n {
+				sum -= valueFn(rows[i-n])
+			}
+			count := i + 1
+			if count > n {
+				count = n
+			}
+			values[i] = sum / float64(count)
+		}
+		return values
+	})
+}
+
+func shiftRows(rows []types.T, offset int) []types.T {
+	values := make([]types.T, len(rows))
+	for i := range rows {
+		if j := i + offset; j >= 0 && j < len(rows) {
+			values[i] = rows[j]
+		}
+	}
+	return values
+}
+
+//peers Returns whether two rows compare equal by orderBy, all rows are peers without orderBy.
+func (w WindowSpec) peers(first types.T, second types.T) bool {
+	return w.orderBy == nil || w.orderBy(first, second) == 0
+}
+
+//apply Add the window operation to the pipeline, compute returns the values of the ordered rows of a partition.
+//The rows are grouped by orderedGroups like GroupBy, keeping the order in which partitions appear, and sorted
+//stably within a partition like SortedStable.
+func (w WindowSpec) apply(compute func(rows []types.T) []types.T) Stream {
+	pipeline := w.s.p
+	pipeline.addOperation(func(next stage) stage {
+		var partitions *orderedGroups
+		var mutex sync.Mutex
+		return newDefaultIntermediateStage(next, beginFunc(func(size int) {
+			partitions = newOrderedGroups(false)
+			next.Begin(size)
+		}), acceptFunc(func(e types.T) {
+			var key types.K
+			if w.partitionBy != nil {
+				key = w.partitionBy(e)
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			partitions.add(key, e)
+		}), endFunc(func() {
+			for _, key := range partitions.keys {
+				rows := partitions.elements[key]
+				if w.orderBy != nil {
+					sortShard(rows, w.orderBy, true)
+				}
+				values := compute(rows)
+				for i := 0; i < len(rows) && !next.CancellationRequested(); i++ {
+					next.Accept(types.WindowValue{Element: rows[i], Value: values[i]})
+				}
+			}
+			partitions = nil
+			next.End()
+		}))
+	})
+	return w.s
+}
@@ -0,0 +1,97 @@
+package stream
+
+import (
+	"github.com/chinalhr/go-stream/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type sale struct {
+	region string
+	amount int
+}
+
+func TestWindowSpec(t *testing.T) {
+	sales := []types.T{
+		sale{"east", 30}, sale{"west", 10}, sale{"east", 10},
+		sale{"east", 30}, sale{"west", 20}, sale{"east", 40},
+	}
+	byRegion := func(e types.T) types.K {
+		return e.(sale).region
+	}
+	byAmount := func(first types.T, second types.T) int {
+		return first.(sale).amount - second.(sale).amount
+	}
+	values := func(result []types.T) []types.T {
+		v := make([]types.T, 0, len(result))
+		for _, e := range result {
+			v = append(v, e.(types.WindowValue).Value)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name   string
+		window func(w WindowSpec) Stream
+		actual []types.T
+	}{
+		{
+			name:   "rowNumberCase",
+			window: WindowSpec.RowNumber,
+			actual: []types.T{1, 2, 3, 4, 1, 2},
+		},
+		{
+			name:   "rankCase",
+			window: WindowSpec.Rank,
+			actual: []types.T{1, 2, 2, 4, 1, 2},
+		},
+		{
+			name:   "denseRankCase",
+			window: WindowSpec.DenseRank,
+			actual: []types.T{1, 2, 2, 3, 1, 2},
+		},
+		{
+			name: "lagCase",
+			window: func(w WindowSpec) Stream {
+				return w.Lag(1)
+			},
+			actual: []types.T{nil, sale{"east", 10}, sale{"east", 30}, sale{"east", 30}, nil, sale{"west", 10}},
+		},
+		{
+			name: "leadCase",
+			window: func(w WindowSpec) Stream {
+				return w.Lead(2)
+			},
+			actual: []types.T{sale{"east", 30}, sale{"east", 40}, nil, nil, nil, nil},
+		},
+		{
+			name: "movingAvgCase",
+			window: func(w WindowSpec) Stream {
+				return w.MovingAvg(2, func(e types.T) float64 {
+					return float64(e.(sale).amount)
+				})
+			},
+			actual: []types.T{10.0, 20.0, 30.0, 35.0, 10.0, 15.0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := test.window(OfSlice(sales).Over(byRegion, byAmount)).ToSlice()
+			assert.Equal(t, test.actual, values(result))
+			assert.Equal(t, sale{"east", 10}, result[0].(types.WindowValue).Element)
+			assert.Equal(t, sale{"west", 10}, result[4].(types.WindowValue).Element)
+		})
+	}
+}
+
+func TestWindowSpec_NoPartition(t *testing.T) {
+	result := OfElements("a", "b", "c").Over(nil, nil).RowNumber().ToSlice()
+	assert.Equal(t, []types.T{
+		types.WindowValue{Element: "a", Value: 1},
+		types.WindowValue{Element: "b", Value: 2},
+		types.WindowValue{Element: "c", Value: 3},
+	}, result)
+
+	assert.Equal(t, []types.T{}, OfElements().Over(nil, nil).Rank().ToSlice())
+}